@@ -0,0 +1,266 @@
+package deckard
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// used if an analyzer does not configure a timeout, toolchains are slow
+const defaultAnalyzerTimeout = 5 * time.Minute
+
+// output of an analyzer beyond this is dropped
+const maxAnalyzerOutput = 1 << 20
+
+// new findings stored per analyzer and commit
+const maxAnalyzerFindings = 50
+
+// localAnalyzer is a command that is run in a worktree of the commit and of
+// its parent, see ConfigAnalyzer.
+type localAnalyzer struct {
+	name    string
+	command []string
+	timeout time.Duration
+	paths   []*regexp.Regexp
+	pattern *regexp.Regexp // nil if every output line is a finding
+	weight  int
+}
+
+// AnalyzerFinding is an output line of an analyzer on the commit that it did
+// not report on the parent.
+type AnalyzerFinding struct {
+	Analyzer string
+	Text     string
+}
+
+// line and column numbers shift between the parent and the commit
+var findingPosition = regexp.MustCompile(`:[0-9]+(:[0-9]+)?`)
+
+func compileAnalyzer(conf ConfigAnalyzer) (*localAnalyzer, error) {
+	if conf.Name == "" {
+		return nil, fmt.Errorf("analyzer without name")
+	}
+	if len(conf.Command) == 0 {
+		return nil, fmt.Errorf("analyzer '%s' without command", conf.Name)
+	}
+	if conf.Timeout < 0 {
+		return nil, fmt.Errorf("analyzer '%s': timeout must not be negative, is %d", conf.Name, conf.Timeout)
+	}
+	if conf.Weight < 0 || conf.Weight > maxSlatScore {
+		return nil, fmt.Errorf("analyzer '%s': weight must be between 0 and %d, is %d", conf.Name, maxSlatScore, conf.Weight)
+	}
+	a := &localAnalyzer{name: conf.Name, command: conf.Command, timeout: defaultAnalyzerTimeout, weight: conf.Weight}
+	if conf.Timeout > 0 {
+		a.timeout = time.Duration(conf.Timeout) * time.Second
+	}
+	for _, glob := range conf.Paths {
+		re, err := globToRegexp(glob)
+		if err != nil {
+			return nil, fmt.Errorf("analyzer '%s': illegal path glob '%s': %w", conf.Name, glob, err)
+		}
+		a.paths = append(a.paths, re)
+	}
+	if conf.Pattern != "" {
+		re, err := regexp.Compile(conf.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("analyzer '%s': illegal pattern '%s': %w", conf.Name, conf.Pattern, err)
+		}
+		a.pattern = re
+	}
+	return a, nil
+}
+
+// applies reports whether the commit changes a file the analyzer is
+// interested in.
+func (a *localAnalyzer) applies(diff *Diff) bool {
+	if len(a.paths) == 0 {
+		return true
+	}
+	for _, stat := range diff.Stats {
+		file := newFileName(stat.File)
+		for _, re := range a.paths {
+			if re.MatchString(file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runAnalyzers runs the analyzers that apply to the commit in a temporary
+// worktree, first at the parent and then at the commit, and returns the
+// findings that are new in the commit. A failing analyzer does not affect the
+// others, the failures are returned as text for the commit.
+func runAnalyzers(analyzers []*localAnalyzer, targetFolder, hash string, diff *Diff) ([]AnalyzerFinding, string, error) {
+	applicable := make([]*localAnalyzer, 0, len(analyzers))
+	for _, a := range analyzers {
+		if a.applies(diff) {
+			applicable = append(applicable, a)
+		}
+	}
+	if len(applicable) == 0 {
+		return nil, "", nil
+	}
+
+	worktree, err := os.MkdirTemp("", "deckard-worktree-")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(worktree)
+	worktree, err = filepath.EvalSymlinks(worktree) // analyzers print resolved paths
+	if err != nil {
+		return nil, "", err
+	}
+
+	// resolved in the clone, a revision like HEAD means another commit in the worktree
+	commitID, err := resolveCommit(targetFolder, hash)
+	if err != nil {
+		return nil, "", err
+	}
+	if commitID == "" {
+		return nil, "", fmt.Errorf("unknown commit %s", hash)
+	}
+	parentID, err := resolveCommit(targetFolder, hash+"^")
+	if err != nil {
+		return nil, "", err
+	}
+	hasParent := parentID != ""
+	start := commitID
+	if hasParent {
+		start = parentID
+	}
+	err = git(targetFolder, "worktree", "add", "--detach", "--force", worktree, start)
+	if err != nil {
+		return nil, "", err
+	}
+	defer git(targetFolder, "worktree", "remove", "--force", worktree)
+
+	failures := make([]string, 0)
+	failed := make(map[*localAnalyzer]bool)
+	parentFindings := make(map[*localAnalyzer]map[string]bool)
+	if hasParent {
+		for _, a := range applicable {
+			lines, err := a.run(worktree)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %s (parent)", a.name, err))
+				failed[a] = true
+				continue
+			}
+			parentFindings[a] = make(map[string]bool)
+			for _, line := range lines {
+				parentFindings[a][findingKey(line)] = true
+			}
+		}
+		// the parent runs may have changed tracked files (lock files, ...) and
+		// left build output behind
+		err = git(worktree, "checkout", "-q", "-f", "--detach", commitID)
+		if err == nil {
+			err = git(worktree, "clean", "-q", "-ffdx")
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("checkout: %s", err))
+			return nil, strings.Join(failures, "; "), nil
+		}
+	}
+
+	findings := make([]AnalyzerFinding, 0)
+	for _, a := range applicable {
+		if failed[a] {
+			continue
+		}
+		lines, err := a.run(worktree)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", a.name, err))
+			continue
+		}
+		found := 0
+		for _, line := range lines {
+			if parentFindings[a][findingKey(line)] {
+				continue
+			}
+			if found < maxAnalyzerFindings {
+				findings = append(findings, AnalyzerFinding{Analyzer: a.name, Text: line})
+			}
+			found++
+		}
+	}
+	return findings, strings.Join(failures, "; "), nil
+}
+
+// run runs the analyzer in the worktree and returns its findings. A non-zero
+// exit code is not an error, most analyzers report findings this way.
+func (a *localAnalyzer) run(worktree string) ([]string, error) {
+	var out bytes.Buffer
+	output := &limitedWriter{w: &out, n: maxAnalyzerOutput, truncate: true}
+	err := runCommand(a.command, worktree, a.timeout, nil, output, output)
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		return nil, err
+	}
+
+	lines := make([]string, 0)
+	for _, line := range strings.Split(out.String(), "\n") {
+		line = strings.TrimSpace(strings.ReplaceAll(line, worktree+string(filepath.Separator), ""))
+		if line == "" || (a.pattern != nil && !a.pattern.MatchString(line)) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// findingKey is the finding without positions, so that findings in code
+// moved by the commit are still matched with the ones of the parent.
+func findingKey(line string) string {
+	return findingPosition.ReplaceAllString(line, ":")
+}
+
+// resolveCommit returns the commit id of the revision, empty if it does not exist.
+func resolveCommit(targetFolder, rev string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	cmd.Dir = targetFolder
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func git(folder string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = folder
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s failed: %w, out=%s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// analyzerReasons returns one reason per analyzer with new findings.
+func analyzerReasons(analyzers []*localAnalyzer, findings []AnalyzerFinding) []Reason {
+	byAnalyzer := make(map[string][]AnalyzerFinding)
+	for _, finding := range findings {
+		byAnalyzer[finding.Analyzer] = append(byAnalyzer[finding.Analyzer], finding)
+	}
+
+	reasons := make([]Reason, 0)
+	for _, a := range analyzers {
+		analyzerFindings := byAnalyzer[a.name]
+		if len(analyzerFindings) == 0 || a.weight == 0 {
+			continue
+		}
+		match := analyzerFindings[0].Text
+		if len(analyzerFindings) > 1 {
+			match += fmt.Sprintf(" (+%d more)", len(analyzerFindings)-1)
+		}
+		reasons = append(reasons, Reason{Rule: "analyzer " + a.name, Match: match, Contribution: a.weight})
+	}
+	return reasons
+}
@@ -0,0 +1,92 @@
+package deckard
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompileAnalyzer(t *testing.T) {
+	tc := []struct {
+		conf ConfigAnalyzer
+		err  string
+	}{
+		{ConfigAnalyzer{Command: []string{"go", "vet"}}, "analyzer without name"},
+		{ConfigAnalyzer{Name: "vet"}, "analyzer 'vet' without command"},
+		{ConfigAnalyzer{Name: "vet", Command: []string{"go"}, Timeout: -1}, "analyzer 'vet': timeout must not be negative, is -1"},
+		{ConfigAnalyzer{Name: "vet", Command: []string{"go"}, Weight: 101}, "analyzer 'vet': weight must be between 0 and 100, is 101"},
+		{ConfigAnalyzer{Name: "vet", Command: []string{"go"}, Pattern: "("}, "analyzer 'vet': illegal pattern '(': error parsing regexp: missing closing ): `(`"},
+	}
+	for _, c := range tc {
+		_, err := compileAnalyzer(c.conf)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("expected error '%s', got %v", c.err, err)
+		}
+	}
+}
+
+func TestFindingKey(t *testing.T) {
+	if findingKey("a.go:12:3: unreachable code") != findingKey("a.go:15:3: unreachable code") {
+		t.Errorf("expected positions to be ignored")
+	}
+	if findingKey("a.go:12: unreachable code") == findingKey("b.go:12: unreachable code") {
+		t.Errorf("expected files to differ")
+	}
+}
+
+func TestAnalyzerApplies(t *testing.T) {
+	a, err := compileAnalyzer(ConfigAnalyzer{Name: "vet", Command: []string{"go", "vet"}, Paths: []string{"*.go", "go.mod"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if !a.applies(&Diff{Stats: []NumStat{{File: "README.md"}, {File: "pkg/a.go"}}}) {
+		t.Errorf("expected analyzer to apply to go files")
+	}
+	if a.applies(&Diff{Stats: []NumStat{{File: "README.md"}}}) {
+		t.Errorf("expected analyzer not to apply to docs")
+	}
+}
+
+func TestRunAnalyzers(t *testing.T) {
+	folder := newTestRepo(t)
+	commitFiles(t, folder, map[string]string{"findings.txt": "a.go:1: old finding\n"})
+	commitFiles(t, folder, map[string]string{"findings.txt": "# comment\na.go:5: old finding\na.go:9: new finding\n"})
+
+	analyzers := make([]*localAnalyzer, 0)
+	for _, conf := range []ConfigAnalyzer{
+		{Name: "cat", Command: []string{"sh", "-c", "cat findings.txt; exit 1"}, Pattern: `\.go:`, Weight: 30},
+		{Name: "slow", Command: []string{"sleep", "5"}, Timeout: 1},
+		{Name: "docs", Command: []string{"false"}, Paths: []string{"*.md"}},
+		{Name: "dirty", Command: []string{"sh", "-c", "test -e build.out && echo a.go:1: stale build; touch build.out; echo x >> findings.txt"}},
+	} {
+		a, err := compileAnalyzer(conf)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		analyzers = append(analyzers, a)
+	}
+
+	diff := &Diff{Stats: []NumStat{{File: "findings.txt", Added: 2}}}
+	findings, failures, err := runAnalyzers(analyzers, folder, "HEAD", diff)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	expected := []AnalyzerFinding{{Analyzer: "cat", Text: "a.go:9: new finding"}}
+	if diff := cmp.Diff(findings, expected); diff != "" {
+		t.Errorf("unexpected findings: %s", diff)
+	}
+	if failures != "slow: timeout after 1s (parent)" {
+		t.Errorf("unexpected failures: %s", failures)
+	}
+	reasons := analyzerReasons(analyzers, findings)
+	if len(reasons) != 1 || reasons[0] != (Reason{Rule: "analyzer cat", Match: "a.go:9: new finding", Contribution: 30}) {
+		t.Errorf("unexpected reasons: %v", reasons)
+	}
+
+	out, err := exec.Command("git", "-C", folder, "worktree", "list").Output()
+	if err != nil || strings.Count(string(out), "\n") != 1 {
+		t.Errorf("expected the temporary worktree to be removed, got %s", out)
+	}
+}
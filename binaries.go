@@ -0,0 +1,143 @@
+package deckard
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	BINARY_EXECUTABLE = "executable"
+	BINARY_ARCHIVE    = "archive"
+	BINARY_TEST_BLOB  = "test_blob" // archive or unknown binary in a test folder
+	BINARY_BLOB       = "blob"      // unknown binary
+	BINARY_MEDIA      = "media"     // images, fonts, documents
+)
+
+// BinaryFile is a binary file added or changed by a commit.
+type BinaryFile struct {
+	File string
+	Kind string // one of the BINARY_ constants
+}
+
+// number of bytes read from a binary to detect its type
+const binaryHeaderSize = 16
+
+var binaryMagics = []struct {
+	magic []byte
+	kind  string
+}{
+	{[]byte("\x7fELF"), BINARY_EXECUTABLE},
+	{[]byte("MZ"), BINARY_EXECUTABLE},
+	{[]byte{0xfe, 0xed, 0xfa, 0xce}, BINARY_EXECUTABLE}, // Mach-O
+	{[]byte{0xfe, 0xed, 0xfa, 0xcf}, BINARY_EXECUTABLE},
+	{[]byte{0xce, 0xfa, 0xed, 0xfe}, BINARY_EXECUTABLE},
+	{[]byte{0xcf, 0xfa, 0xed, 0xfe}, BINARY_EXECUTABLE},
+	{[]byte{0xca, 0xfe, 0xba, 0xbe}, BINARY_EXECUTABLE}, // Mach-O universal or java class
+	{[]byte("\x00asm"), BINARY_EXECUTABLE},
+	{[]byte("!<arch>\n"), BINARY_EXECUTABLE}, // static library
+	{[]byte("PK\x03\x04"), BINARY_ARCHIVE},
+	{[]byte{0x1f, 0x8b}, BINARY_ARCHIVE},
+	{[]byte("\xfd7zXZ\x00"), BINARY_ARCHIVE},
+	{[]byte("BZh"), BINARY_ARCHIVE},
+	{[]byte("7z\xbc\xaf\x27\x1c"), BINARY_ARCHIVE},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, BINARY_ARCHIVE}, // zstd
+	{[]byte("Rar!"), BINARY_ARCHIVE},
+	{[]byte("\x89PNG"), BINARY_MEDIA},
+	{[]byte{0xff, 0xd8, 0xff}, BINARY_MEDIA},
+	{[]byte("GIF8"), BINARY_MEDIA},
+	{[]byte("%PDF"), BINARY_MEDIA},
+	{[]byte("wOFF"), BINARY_MEDIA},
+	{[]byte("wOF2"), BINARY_MEDIA},
+}
+
+var binaryExtensions = map[string]string{
+	".exe": BINARY_EXECUTABLE, ".dll": BINARY_EXECUTABLE, ".so": BINARY_EXECUTABLE, ".dylib": BINARY_EXECUTABLE,
+	".a": BINARY_EXECUTABLE, ".o": BINARY_EXECUTABLE, ".bin": BINARY_EXECUTABLE, ".class": BINARY_EXECUTABLE,
+	".wasm": BINARY_EXECUTABLE, ".node": BINARY_EXECUTABLE,
+	".zip": BINARY_ARCHIVE, ".tar": BINARY_ARCHIVE, ".gz": BINARY_ARCHIVE, ".tgz": BINARY_ARCHIVE,
+	".xz": BINARY_ARCHIVE, ".lzma": BINARY_ARCHIVE, ".bz2": BINARY_ARCHIVE, ".zst": BINARY_ARCHIVE,
+	".7z": BINARY_ARCHIVE, ".rar": BINARY_ARCHIVE, ".jar": BINARY_ARCHIVE, ".whl": BINARY_ARCHIVE,
+	".png": BINARY_MEDIA, ".jpg": BINARY_MEDIA, ".jpeg": BINARY_MEDIA, ".gif": BINARY_MEDIA,
+	".ico": BINARY_MEDIA, ".webp": BINARY_MEDIA, ".pdf": BINARY_MEDIA, ".ttf": BINARY_MEDIA,
+	".otf": BINARY_MEDIA, ".woff": BINARY_MEDIA, ".woff2": BINARY_MEDIA,
+}
+
+var testFolder = regexp.MustCompile(`(^|/)(tests?|testdata|fixtures?|__tests__|spec)/`)
+
+// classifyBinary determines the kind of a binary file from its header,
+// falling back to the file extension. Media files are never test blobs,
+// the content of a test folder is otherwise suspicious if binary.
+func classifyBinary(file string, header []byte) string {
+	kind := ""
+	for _, magic := range binaryMagics {
+		if bytes.HasPrefix(header, magic.magic) {
+			kind = magic.kind
+			break
+		}
+	}
+	if kind == "" {
+		kind = binaryExtensions[strings.ToLower(path.Ext(file))]
+	}
+
+	if testFolder.MatchString(file) && (kind == "" || kind == BINARY_ARCHIVE) {
+		return BINARY_TEST_BLOB
+	}
+	if kind == "" {
+		return BINARY_BLOB
+	}
+	return kind
+}
+
+// binaryFiles classifies the binary files of the diff that exist after the
+// commit (deleted binaries are ignored).
+func binaryFiles(targetFolder, hash string, diff *Diff) ([]BinaryFile, error) {
+	binaries := make([]BinaryFile, 0)
+	for _, stat := range diff.Stats {
+		if !stat.Binary {
+			continue
+		}
+		file := newFileName(stat.File) // a renamed binary may have new content as well
+		header, found, err := headFile(targetFolder, hash, file, binaryHeaderSize)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		binaries = append(binaries, BinaryFile{File: file, Kind: classifyBinary(file, header)})
+	}
+	return binaries, nil
+}
+
+var defaultBinaryWeights = map[string]int{
+	BINARY_EXECUTABLE: 100,
+	BINARY_ARCHIVE:    60,
+	BINARY_TEST_BLOB:  80,
+	BINARY_BLOB:       50,
+	BINARY_MEDIA:      0,
+}
+
+// binaryReasons returns one reason per kind of binary the commit adds or changes.
+func binaryReasons(weights map[string]int, binaries []BinaryFile) []Reason {
+	byKind := make(map[string][]string)
+	for _, binary := range binaries {
+		byKind[binary.Kind] = append(byKind[binary.Kind], binary.File)
+	}
+
+	reasons := make([]Reason, 0)
+	for _, kind := range []string{BINARY_EXECUTABLE, BINARY_TEST_BLOB, BINARY_ARCHIVE, BINARY_BLOB, BINARY_MEDIA} {
+		files := byKind[kind]
+		if len(files) == 0 || weights[kind] == 0 {
+			continue
+		}
+		match := files[0]
+		if len(files) > 1 {
+			match += fmt.Sprintf(" (+%d more)", len(files)-1)
+		}
+		reasons = append(reasons, Reason{Rule: "binary " + strings.ReplaceAll(kind, "_", " "), Match: match, Contribution: weights[kind]})
+	}
+	return reasons
+}
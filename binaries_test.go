@@ -0,0 +1,33 @@
+package deckard
+
+import (
+	"testing"
+)
+
+func TestClassifyBinary(t *testing.T) {
+	tc := []struct {
+		desc     string
+		file     string
+		header   []byte
+		expected string
+	}{
+		{desc: "elf executable without extension", file: "bin/tool", header: []byte("\x7fELF\x02\x01"), expected: BINARY_EXECUTABLE},
+		{desc: "windows executable", file: "tool.exe", header: []byte("MZ\x90\x00"), expected: BINARY_EXECUTABLE},
+		{desc: "executable in test folder", file: "testdata/helper", header: []byte("\x7fELF"), expected: BINARY_EXECUTABLE},
+		{desc: "xz archive in tests", file: "tests/files/bad-3-corrupt_lzma2.xz", header: []byte("\xfd7zXZ\x00\x00"), expected: BINARY_TEST_BLOB},
+		{desc: "unknown blob in tests", file: "tests/files/good-large_compressed.lzma", header: []byte{0x5d, 0x00, 0x00}, expected: BINARY_TEST_BLOB},
+		{desc: "zip archive", file: "dist/release.zip", header: []byte("PK\x03\x04"), expected: BINARY_ARCHIVE},
+		{desc: "archive by extension", file: "vendor.tar", header: []byte("vendor/\x00\x00"), expected: BINARY_ARCHIVE},
+		{desc: "image", file: "docs/logo.png", header: []byte("\x89PNG\r\n"), expected: BINARY_MEDIA},
+		{desc: "image in tests", file: "test/golden.png", header: []byte("\x89PNG\r\n"), expected: BINARY_MEDIA},
+		{desc: "unknown blob", file: "data/model.dat", header: []byte{0x01, 0x02, 0x03}, expected: BINARY_BLOB},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			if kind := classifyBinary(c.file, c.header); kind != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, kind)
+			}
+		})
+	}
+}
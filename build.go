@@ -0,0 +1,151 @@
+package deckard
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	BUILD_MAKEFILE        = "makefile"
+	BUILD_DOCKERFILE      = "dockerfile"
+	BUILD_CI              = "ci"      // GitHub workflows and actions, GitLab CI and others
+	BUILD_RELEASE         = "release" // goreleaser and similar
+	BUILD_INSTALL_SCRIPT  = "install_script"
+	BUILD_UNPINNED_ACTION = "unpinned_action" // GitHub action not referenced by commit SHA
+	BUILD_UNPINNED_IMAGE  = "unpinned_image"  // Docker base image not pinned by digest
+)
+
+// weights used if not overwritten in the config
+var defaultBuildWeights = map[string]int{
+	BUILD_MAKEFILE:        20,
+	BUILD_DOCKERFILE:      30,
+	BUILD_CI:              40,
+	BUILD_RELEASE:         40,
+	BUILD_INSTALL_SCRIPT:  50,
+	BUILD_UNPINNED_ACTION: 30,
+	BUILD_UNPINNED_IMAGE:  20,
+}
+
+var buildFilePatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{BUILD_CI, regexp.MustCompile(`^\.github/(workflows/[^/]+\.ya?ml|actions/.+/action\.ya?ml)$|(^|/)action\.ya?ml$`)},
+	{BUILD_CI, regexp.MustCompile(`(^|/)\.gitlab-ci\.ya?ml$|^\.gitlab/ci/.+\.ya?ml$`)},
+	{BUILD_CI, regexp.MustCompile(`^\.circleci/config\.ya?ml$|^\.travis\.ya?ml$|^azure-pipelines\.ya?ml$|(^|/)Jenkinsfile$|^\.buildkite/`)},
+	{BUILD_RELEASE, regexp.MustCompile(`(^|/)\.?goreleaser\.ya?ml$`)},
+	{BUILD_DOCKERFILE, regexp.MustCompile(`(^|/)(Dockerfile|Containerfile)(\.[^/]*)?$|\.[dD]ockerfile$`)},
+	{BUILD_MAKEFILE, regexp.MustCompile(`(^|/)(Makefile|makefile|GNUmakefile)$|\.mk$`)},
+	{BUILD_INSTALL_SCRIPT, regexp.MustCompile(`(^|/)(install|get|bootstrap)[^/]*\.(sh|bash|ps1)$`)},
+}
+
+// buildFileKind returns the BUILD_ kind of the file or "" if it is not part
+// of the build or release infrastructure.
+func buildFileKind(file string) string {
+	file = path.Clean(file)
+	for _, p := range buildFilePatterns {
+		if p.re.MatchString(file) {
+			return p.kind
+		}
+	}
+	return ""
+}
+
+var (
+	actionUsesRegexp = regexp.MustCompile(`^\s*(-\s+)?uses:\s*['"]?([^'"\s#]+)`)
+	commitSHARegexp  = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	dockerFromRegexp = regexp.MustCompile(`(?i)^\s*FROM\s+(--\S+\s+)*(\S+)(\s+AS\s+(\S+))?`)
+)
+
+// unpinnedActions returns the added GitHub action references that do not
+// point to a full commit SHA. Local actions are ignored.
+func unpinnedActions(patch FilePatch) []Finding {
+	findings := make([]Finding, 0)
+	for _, line := range patch.Added {
+		m := actionUsesRegexp.FindStringSubmatch(line.Text)
+		if m == nil {
+			continue
+		}
+		action := m[2]
+		if strings.HasPrefix(action, "./") {
+			continue
+		}
+		if strings.HasPrefix(action, "docker://") {
+			if strings.Contains(action, "@sha256:") {
+				continue
+			}
+		} else if at := strings.LastIndex(action, "@"); at >= 0 && commitSHARegexp.MatchString(action[at+1:]) {
+			continue
+		}
+		findings = append(findings, Finding{File: patch.File, Line: line.Num, Kind: BUILD_UNPINNED_ACTION, Text: action})
+	}
+	return findings
+}
+
+// unpinnedImages returns the added base images of a Dockerfile that are not
+// pinned by digest. Only stages named in the added lines are recognized as
+// such, images from build args are skipped.
+func unpinnedImages(patch FilePatch) []Finding {
+	stages := map[string]bool{"scratch": true}
+	for _, line := range patch.Added {
+		m := dockerFromRegexp.FindStringSubmatch(line.Text)
+		if m != nil && m[4] != "" {
+			stages[strings.ToLower(m[4])] = true
+		}
+	}
+
+	findings := make([]Finding, 0)
+	for _, line := range patch.Added {
+		m := dockerFromRegexp.FindStringSubmatch(line.Text)
+		if m == nil {
+			continue
+		}
+		image := m[2]
+		if stages[strings.ToLower(image)] || strings.Contains(image, "@sha256:") || strings.Contains(image, "$") {
+			continue
+		}
+		findings = append(findings, Finding{File: patch.File, Line: line.Num, Kind: BUILD_UNPINNED_IMAGE, Text: image})
+	}
+	return findings
+}
+
+// buildReasons returns one reason per kind of changed build file and one per
+// failed pinning check.
+func buildReasons(weights map[string]int, diff *Diff) []Reason {
+	byKind := make(map[string][]string)
+	for _, stat := range diff.Stats {
+		file := newFileName(stat.File)
+		kind := buildFileKind(file)
+		if kind != "" {
+			byKind[kind] = append(byKind[kind], file)
+		}
+	}
+	for _, patch := range diff.Patch {
+		var findings []Finding
+		switch buildFileKind(patch.File) {
+		case BUILD_CI:
+			findings = unpinnedActions(patch)
+		case BUILD_DOCKERFILE:
+			findings = unpinnedImages(patch)
+		}
+		for _, finding := range findings {
+			byKind[finding.Kind] = append(byKind[finding.Kind], finding.String())
+		}
+	}
+
+	reasons := make([]Reason, 0)
+	for _, kind := range []string{BUILD_INSTALL_SCRIPT, BUILD_CI, BUILD_RELEASE, BUILD_DOCKERFILE, BUILD_MAKEFILE, BUILD_UNPINNED_ACTION, BUILD_UNPINNED_IMAGE} {
+		matches := byKind[kind]
+		if len(matches) == 0 || weights[kind] == 0 {
+			continue
+		}
+		match := matches[0]
+		if len(matches) > 1 {
+			match += fmt.Sprintf(" (+%d more)", len(matches)-1)
+		}
+		reasons = append(reasons, Reason{Rule: "build " + strings.ReplaceAll(kind, "_", " "), Match: match, Contribution: weights[kind]})
+	}
+	return reasons
+}
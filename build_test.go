@@ -0,0 +1,112 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildFileKind(t *testing.T) {
+	tc := []struct {
+		file     string
+		expected string
+	}{
+		{file: "main.go", expected: ""},
+		{file: "Makefile", expected: BUILD_MAKEFILE},
+		{file: "build/rules.mk", expected: BUILD_MAKEFILE},
+		{file: "Dockerfile", expected: BUILD_DOCKERFILE},
+		{file: "images/Dockerfile.alpine", expected: BUILD_DOCKERFILE},
+		{file: "build.dockerfile", expected: BUILD_DOCKERFILE},
+		{file: ".github/workflows/ci.yml", expected: BUILD_CI},
+		{file: ".github/actions/setup/action.yaml", expected: BUILD_CI},
+		{file: ".github/dependabot.yml", expected: ""},
+		{file: ".gitlab-ci.yml", expected: BUILD_CI},
+		{file: "Jenkinsfile", expected: BUILD_CI},
+		{file: ".goreleaser.yaml", expected: BUILD_RELEASE},
+		{file: "install.sh", expected: BUILD_INSTALL_SCRIPT},
+		{file: "scripts/get-helm-3.sh", expected: BUILD_INSTALL_SCRIPT},
+		{file: "scripts/test.sh", expected: ""},
+	}
+
+	for _, c := range tc {
+		t.Run(c.file, func(t *testing.T) {
+			if kind := buildFileKind(c.file); kind != c.expected {
+				t.Errorf("expected kind '%s', got '%s'", c.expected, kind)
+			}
+		})
+	}
+}
+
+func TestBuildReasons(t *testing.T) {
+	weights, err := mergeWeights("build", defaultBuildWeights, map[string]int{BUILD_MAKEFILE: 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	tc := []struct {
+		desc     string
+		diff     *Diff
+		expected []Reason
+	}{
+		{
+			desc:     "no build files",
+			diff:     &Diff{Stats: []NumStat{{File: "main.go", Added: 1}}},
+			expected: []Reason{},
+		},
+		{
+			desc:     "disabled kind",
+			diff:     &Diff{Stats: []NumStat{{File: "Makefile", Added: 1}}},
+			expected: []Reason{},
+		},
+		{
+			desc: "renamed into workflows",
+			diff: &Diff{Stats: []NumStat{{File: ".github/{ci.yml => workflows/ci.yml}"}}},
+			expected: []Reason{
+				{Rule: "build ci", Match: ".github/workflows/ci.yml", Contribution: 40},
+			},
+		},
+		{
+			desc: "unpinned actions",
+			diff: &Diff{
+				Stats: []NumStat{{File: ".github/workflows/ci.yml", Added: 5}, {File: ".github/workflows/release.yml", Added: 1}},
+				Patch: []FilePatch{{File: ".github/workflows/ci.yml", Added: []PatchLine{
+					{Num: 10, Text: "      - uses: actions/checkout@v4"},
+					{Num: 11, Text: "      - uses: actions/setup-go@0c52d547c9bc32b1aa3301fd7a9cb496313a4491 # v5"},
+					{Num: 12, Text: "      - uses: ./.github/actions/setup"},
+					{Num: 13, Text: "        uses: 'docker://alpine:3.19'"},
+					{Num: 14, Text: "      - run: make"},
+				}}},
+			},
+			expected: []Reason{
+				{Rule: "build ci", Match: ".github/workflows/ci.yml (+1 more)", Contribution: 40},
+				{Rule: "build unpinned action", Match: ".github/workflows/ci.yml:10 actions/checkout@v4 (+1 more)", Contribution: 30},
+			},
+		},
+		{
+			desc: "unpinned images",
+			diff: &Diff{
+				Stats: []NumStat{{File: "Dockerfile", Added: 5}},
+				Patch: []FilePatch{{File: "Dockerfile", Added: []PatchLine{
+					{Num: 1, Text: "FROM --platform=$BUILDPLATFORM golang:1.22 AS build"},
+					{Num: 2, Text: "FROM ${BASE_IMAGE}"},
+					{Num: 3, Text: "FROM gcr.io/distroless/static@sha256:41972110a1c1a5c0b6adb283e8aa092c43c31f7c5d79b8656fbffff2c3e61f05"},
+					{Num: 4, Text: "COPY --from=build /app /app"},
+					{Num: 5, Text: "from BUILD"},
+				}}},
+			},
+			expected: []Reason{
+				{Rule: "build dockerfile", Match: "Dockerfile", Contribution: 30},
+				{Rule: "build unpinned image", Match: "Dockerfile:1 golang:1.22", Contribution: 20},
+			},
+		},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			reasons := buildReasons(weights, c.diff)
+			if diff := cmp.Diff(reasons, c.expected); diff != "" {
+				t.Errorf("unexpected reasons: %s", diff)
+			}
+		})
+	}
+}
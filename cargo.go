@@ -0,0 +1,119 @@
+package deckard
+
+import (
+	"fmt"
+	"path"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// cargoAnalyzer handles Cargo.toml and Cargo.lock of rust projects.
+type cargoAnalyzer struct{}
+
+func (cargoAnalyzer) handles(file string) bool {
+	base := path.Base(file)
+	return base == "Cargo.toml" || base == "Cargo.lock"
+}
+
+func (cargoAnalyzer) ecosystem() string {
+	return "crates.io"
+}
+
+func (cargoAnalyzer) diff(manifest, before, after string) ([]DependencyChange, error) {
+	parse := parseCargoToml
+	if path.Base(manifest) == "Cargo.lock" {
+		parse = parseCargoLock
+	}
+
+	versionsBefore, err := parse(before)
+	if err != nil {
+		return nil, err
+	}
+	versionsAfter, err := parse(after)
+	if err != nil {
+		return nil, err
+	}
+	return diffVersions(manifest, versionsBefore, versionsAfter), nil
+}
+
+var cargoDependencySections = []string{"dependencies", "dev-dependencies", "build-dependencies"}
+
+func parseCargoToml(content string) (map[string]string, error) {
+	var doc map[string]interface{}
+	err := toml.Unmarshal([]byte(content), &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	addSections := func(table map[string]interface{}) {
+		for _, section := range cargoDependencySections {
+			deps, _ := table[section].(map[string]interface{})
+			for name, spec := range deps {
+				versions[name] = cargoVersion(spec)
+			}
+		}
+	}
+
+	addSections(doc)
+	if workspace, ok := doc["workspace"].(map[string]interface{}); ok {
+		addSections(workspace)
+	}
+	if targets, ok := doc["target"].(map[string]interface{}); ok {
+		for _, target := range targets {
+			if targetTable, ok := target.(map[string]interface{}); ok {
+				addSections(targetTable)
+			}
+		}
+	}
+	return versions, nil
+}
+
+// cargoVersion returns the version of a dependency that is either given
+// as plain string or as table with version, git or path key.
+func cargoVersion(spec interface{}) string {
+	switch s := spec.(type) {
+	case string:
+		return s
+	case map[string]interface{}:
+		if version, ok := s["version"].(string); ok {
+			return version
+		}
+		if git, ok := s["git"].(string); ok {
+			for _, ref := range []string{"rev", "tag", "branch"} {
+				if value, ok := s[ref].(string); ok {
+					return fmt.Sprintf("git %s#%s", git, value)
+				}
+			}
+			return "git " + git
+		}
+		if p, ok := s["path"].(string); ok {
+			return "path " + p
+		}
+		if workspace, ok := s["workspace"].(bool); ok && workspace {
+			return "workspace"
+		}
+	}
+	return ""
+}
+
+type cargoLock struct {
+	Packages []struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+	} `toml:"package"`
+}
+
+func parseCargoLock(content string) (map[string]string, error) {
+	var lock cargoLock
+	err := toml.Unmarshal([]byte(content), &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	for _, pkg := range lock.Packages {
+		addVersion(versions, pkg.Name, pkg.Version)
+	}
+	return versions, nil
+}
@@ -0,0 +1,113 @@
+package deckard
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	defaultChurnWeight     = 40
+	defaultChurnThreshold  = 3.5
+	defaultChurnMinHistory = 20
+	churnHistorySize       = 1000 // number of most recent commits the baseline is computed from
+)
+
+// ChurnSample is the size of a single commit.
+type ChurnSample struct {
+	Lines uint64
+	Files uint64
+}
+
+// churnBaseline is the typical commit size of a project. The statistics are
+// computed on log(1+x) as commit sizes are heavily skewed.
+type churnBaseline struct {
+	medianLines float64
+	medianFiles float64
+	logLines    robustStats
+	logFiles    robustStats
+}
+
+type robustStats struct {
+	median float64
+	mad    float64 // median absolute deviation
+}
+
+// minimal deviation in log space, prevents that projects with (nearly)
+// identical commit sizes flag every slightly bigger commit
+const minLogMAD = 0.5
+
+// newChurnBaseline computes the baseline from the samples, nil if there are
+// not enough samples.
+func newChurnBaseline(samples []ChurnSample, minHistory int) *churnBaseline {
+	if len(samples) == 0 || len(samples) < minHistory {
+		return nil
+	}
+
+	lines := make([]float64, len(samples))
+	files := make([]float64, len(samples))
+	for i, sample := range samples {
+		lines[i] = float64(sample.Lines)
+		files[i] = float64(sample.Files)
+	}
+	return &churnBaseline{
+		medianLines: median(lines),
+		medianFiles: median(files),
+		logLines:    newRobustStats(logValues(lines)),
+		logFiles:    newRobustStats(logValues(files)),
+	}
+}
+
+func newRobustStats(values []float64) robustStats {
+	m := median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - m)
+	}
+	return robustStats{median: m, mad: math.Max(median(deviations), minLogMAD)}
+}
+
+// zScore returns the robust z-score of value, positive values are above the median.
+func (s robustStats) zScore(value float64) float64 {
+	return (math.Log1p(value) - s.median) / (1.4826 * s.mad)
+}
+
+func logValues(values []float64) []float64 {
+	logs := make([]float64, len(values))
+	for i, v := range values {
+		logs[i] = math.Log1p(v)
+	}
+	return logs
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// churnReason returns a reason if the commit is an outlier compared to the
+// project's baseline.
+func churnReason(baseline *churnBaseline, sample ChurnSample, weight int, threshold float64) (Reason, bool) {
+	if baseline == nil || weight == 0 {
+		return Reason{}, false
+	}
+	zLines := baseline.logLines.zScore(float64(sample.Lines))
+	zFiles := baseline.logFiles.zScore(float64(sample.Files))
+	if zLines < threshold && zFiles < threshold {
+		return Reason{}, false
+	}
+	match := fmt.Sprintf("%d lines in %d files (median %.0f lines in %.0f files)", sample.Lines, sample.Files, baseline.medianLines, baseline.medianFiles)
+	return Reason{Rule: "unusual churn", Match: match, Contribution: weight}, true
+}
+
+func diffChurn(diff *Diff) ChurnSample {
+	return ChurnSample{Lines: churn(diff), Files: uint64(len(diff.Stats))}
+}
@@ -0,0 +1,59 @@
+package deckard
+
+import (
+	"testing"
+)
+
+func samples(lines ...uint64) []ChurnSample {
+	result := make([]ChurnSample, 0, len(lines))
+	for _, l := range lines {
+		result = append(result, ChurnSample{Lines: l, Files: 1 + l/100})
+	}
+	return result
+}
+
+func TestChurnReason(t *testing.T) {
+	smallLibrary := samples(3, 5, 8, 10, 12, 4, 20, 6, 15, 9, 7, 30, 11, 2, 25, 14, 5, 8, 19, 10)
+	bigProject := samples(800, 1500, 20000, 3000, 12000, 600, 9000, 25000, 400, 5000, 7000, 15000, 2000, 900, 18000, 4000, 30000, 1000, 6000, 10000)
+
+	tc := []struct {
+		desc     string
+		history  []ChurnSample
+		sample   ChurnSample
+		expected bool
+	}{
+		{desc: "typical commit of a small library", history: smallLibrary, sample: ChurnSample{Lines: 12, Files: 1}, expected: false},
+		{desc: "huge commit to a small library", history: smallLibrary, sample: ChurnSample{Lines: 2000, Files: 3}, expected: true},
+		{desc: "many files in a small library", history: smallLibrary, sample: ChurnSample{Lines: 20, Files: 80}, expected: true},
+		{desc: "huge commit to a big project", history: bigProject, sample: ChurnSample{Lines: 20000, Files: 201}, expected: false},
+		{desc: "not enough history", history: smallLibrary[:5], sample: ChurnSample{Lines: 2000, Files: 3}, expected: false},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			baseline := newChurnBaseline(c.history, defaultChurnMinHistory)
+			_, flagged := churnReason(baseline, c.sample, defaultChurnWeight, defaultChurnThreshold)
+			if flagged != c.expected {
+				t.Errorf("expected flagged = %t, got %t", c.expected, flagged)
+			}
+		})
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tc := []struct {
+		values   []float64
+		expected float64
+	}{
+		{[]float64{}, 0},
+		{[]float64{3}, 3},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+
+	for _, c := range tc {
+		if result := median(c.values); result != c.expected {
+			t.Errorf("median(%v): expected %f, got %f", c.values, c.expected, result)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"database/sql"
+	"fmt"
+	"os"
+
 	"github.com/Ragnaroek/deckard"
 )
 
@@ -15,6 +19,42 @@ func main() {
 		panic(err)
 	}
 
+	// commands that work on the stored commits only: deckard <command> [project]
+	command, project := "", ""
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	if len(os.Args) > 2 {
+		project = os.Args[2]
+	}
+	switch command {
+	case "rescore":
+		err = deckard.Rescore(config, db, project, func(project string, done, total int) {
+			fmt.Printf("\rRescoring %s: %d/%d", project, done, total)
+			if done == total {
+				fmt.Println()
+			}
+		})
+		if err != nil {
+			panic(err)
+		}
+		return
+	case "refit":
+		_, err = deckard.RefitWeights(config, db, project, true)
+		if err != nil {
+			panic(err)
+		}
+		printWeightReport(config, db, project)
+		return
+	case "weights":
+		printWeightReport(config, db, project)
+		return
+	case "":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command '%s', expected rescore, refit or weights\n", command)
+		os.Exit(2)
+	}
+
 	ui, err := deckard.BuildUI(config, db)
 	if err != nil {
 		panic(err)
@@ -33,3 +73,11 @@ func main() {
 		panic(err)
 	}
 }
+
+func printWeightReport(config *deckard.Config, db *sql.DB, project string) {
+	report, err := deckard.WeightReport(config, db, project)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(report)
+}
@@ -0,0 +1,186 @@
+package deckard
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// locations of the CODEOWNERS file, in the order GitHub looks them up
+var codeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type codeOwnersRule struct {
+	re     *regexp.Regexp
+	owners []string
+}
+
+type codeOwners struct {
+	rules []codeOwnersRule
+}
+
+// loadCodeOwners reads the CODEOWNERS file at the revision, nil if the
+// project has none.
+func loadCodeOwners(targetFolder, rev string) (*codeOwners, error) {
+	for _, location := range codeOwnersLocations {
+		content, found, err := showFile(targetFolder, rev, location)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return parseCodeOwners(content)
+		}
+	}
+	return nil, nil
+}
+
+func parseCodeOwners(content string) (*codeOwners, error) {
+	owners := &codeOwners{}
+	for num, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue // empty line or GitLab section header
+		}
+		re, err := codeOwnersRegexp(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("CODEOWNERS line %d: %w", num+1, err)
+		}
+		owners.rules = append(owners.rules, codeOwnersRule{re: re, owners: fields[1:]})
+	}
+	return owners, nil
+}
+
+// codeOwnersRegexp converts a CODEOWNERS pattern, which follows the
+// gitignore rules, to a regex.
+func codeOwnersRegexp(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	prefix := "^"
+	if !anchored {
+		prefix = "^(.*/)?"
+	}
+	suffix := "(/.*)?$" // a matched directory owns everything below it
+	if dirOnly {
+		suffix = "/.*$"
+	} else if strings.Contains(pattern[strings.LastIndex(pattern, "/")+1:], "*") {
+		suffix = "$" // like GitHub, docs/* does not match nested files
+	}
+	return regexp.Compile(prefix + globPattern(pattern) + suffix)
+}
+
+// ownersOf returns the owners of the file, the last matching rule wins.
+func (c *codeOwners) ownersOf(file string) []string {
+	if c == nil {
+		return nil
+	}
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].re.MatchString(file) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// fileOwners maps every file of the diff that has owners to them. A renamed
+// file is mapped with its old and its new name, moving a file out of an owned
+// path needs the owners as well.
+func fileOwners(owners *codeOwners, diff *Diff) map[string][]string {
+	result := make(map[string][]string)
+	for _, stat := range diff.Stats {
+		for _, file := range []string{oldFileName(stat.File), newFileName(stat.File)} {
+			if fileOwners := owners.ownersOf(file); len(fileOwners) > 0 {
+				result[file] = fileOwners
+			}
+		}
+	}
+	return result
+}
+
+// allOwners returns the sorted, unique owners of all files.
+func allOwners(owners map[string][]string) []string {
+	unique := make(map[string]bool)
+	for _, fileOwners := range owners {
+		for _, owner := range fileOwners {
+			unique[owner] = true
+		}
+	}
+	result := make([]string, 0, len(unique))
+	for owner := range unique {
+		result = append(result, owner)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// isCodeOwner reports whether the commit's author is one of the owners. A
+// team (@org/team) is resolved with the configured team members.
+func isCodeOwner(conf ConfigCodeOwners, owners []string, commit *Commit) bool {
+	for _, owner := range owners {
+		if strings.HasPrefix(owner, "@") && strings.Contains(owner, "/") {
+			for _, member := range conf.Teams[owner] {
+				if matchesOwner(member, commit) {
+					return true
+				}
+			}
+			continue
+		}
+		if matchesOwner(owner, commit) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesOwner matches a user (@login) or an email address against the
+// author of the commit. Without a login in the commit data the login is
+// compared with the author name and the local part of the email address.
+func matchesOwner(owner string, commit *Commit) bool {
+	email := strings.ToLower(commit.AuthorEmail)
+	if !strings.HasPrefix(owner, "@") {
+		return strings.ToLower(owner) == email
+	}
+
+	login := strings.ToLower(strings.TrimPrefix(owner, "@"))
+	if login == strings.ToLower(commit.AuthorName) {
+		return true
+	}
+	local := strings.Split(email, "@")[0]
+	if strings.HasSuffix(email, "@users.noreply.github.com") {
+		if i := strings.Index(local, "+"); i >= 0 { // 12345+login@users.noreply.github.com
+			local = local[i+1:]
+		}
+	}
+	return local == login
+}
+
+// codeOwnersReason returns a reason if the author changed files without
+// being one of their owners.
+func codeOwnersReason(conf ConfigCodeOwners, owners map[string][]string, commit *Commit) (Reason, bool) {
+	if conf.Weight == 0 || len(owners) == 0 {
+		return Reason{}, false
+	}
+
+	files := make([]string, 0, len(owners))
+	for file := range owners {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	foreign := make([]string, 0)
+	for _, file := range files {
+		if !isCodeOwner(conf, owners[file], commit) {
+			foreign = append(foreign, file)
+		}
+	}
+	if len(foreign) == 0 {
+		return Reason{}, false
+	}
+	match := fmt.Sprintf("%d of %d owned files, e.g. %s (%s)", len(foreign), len(files), foreign[0], strings.Join(owners[foreign[0]], " "))
+	return Reason{Rule: "not code owner", Match: match, Contribution: conf.Weight}, true
+}
@@ -0,0 +1,109 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testCodeOwners = `# default owners
+*       @org/core
+
+*.js    @js-owner
+/build/ @builder jane@example.com
+docs/*  docs@example.com
+apps/   @octocat
+/scripts/**/deploy.sh @ops # deployment
+
+[GitLab Section]
+`
+
+func TestCodeOwners(t *testing.T) {
+	owners, err := parseCodeOwners(testCodeOwners)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	tc := []struct {
+		file     string
+		expected []string
+	}{
+		{"main.go", []string{"@org/core"}},
+		{"web/app.js", []string{"@js-owner"}},
+		{"build/ci/run.sh", []string{"@builder", "jane@example.com"}},
+		{"src/build/file.go", []string{"@org/core"}},
+		{"docs/index.md", []string{"docs@example.com"}},
+		{"docs/api/index.md", []string{"@org/core"}},
+		{"pkg/apps/x.go", []string{"@octocat"}},
+		{"scripts/deploy.sh", []string{"@ops"}},
+		{"scripts/prod/eu/deploy.sh", []string{"@ops"}},
+	}
+
+	for _, c := range tc {
+		t.Run(c.file, func(t *testing.T) {
+			if diff := cmp.Diff(owners.ownersOf(c.file), c.expected); diff != "" {
+				t.Errorf("unexpected owners: %s", diff)
+			}
+		})
+	}
+}
+
+func TestFileOwners(t *testing.T) {
+	owners, err := parseCodeOwners(testCodeOwners)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	diff := &Diff{Stats: []NumStat{
+		{File: "main.go", Added: 1},
+		{File: "{build => tools}/ci/run.sh", Added: 1},
+		{File: "docs/a.md => notes/a.md"},
+	}}
+	expected := map[string][]string{
+		"main.go":         {"@org/core"},
+		"build/ci/run.sh": {"@builder", "jane@example.com"},
+		"tools/ci/run.sh": {"@org/core"},
+		"docs/a.md":       {"docs@example.com"},
+		"notes/a.md":      {"@org/core"},
+	}
+	if diff := cmp.Diff(fileOwners(owners, diff), expected); diff != "" {
+		t.Errorf("unexpected owners: %s", diff)
+	}
+}
+
+func TestCodeOwnersReason(t *testing.T) {
+	conf := ConfigCodeOwners{Weight: 30, Teams: map[string][]string{"@org/core": {"@alice", "bob@example.com"}}}
+	owners := map[string][]string{
+		"main.go":  {"@org/core"},
+		"build.sh": {"@builder", "jane@example.com"},
+	}
+
+	tc := []struct {
+		desc     string
+		owners   []string
+		commit   *Commit
+		expected bool
+	}{
+		{desc: "team member by login", owners: owners["main.go"], commit: &Commit{AuthorName: "alice"}, expected: true},
+		{desc: "team member by email", owners: owners["main.go"], commit: &Commit{AuthorName: "Bob", AuthorEmail: "Bob@example.com"}, expected: true},
+		{desc: "owner by github noreply email", owners: owners["build.sh"], commit: &Commit{AuthorName: "B. Uilder", AuthorEmail: "123+builder@users.noreply.github.com"}, expected: true},
+		{desc: "owner by email", owners: owners["build.sh"], commit: &Commit{AuthorName: "Jane", AuthorEmail: "jane@example.com"}, expected: true},
+		{desc: "stranger", owners: owners["main.go"], commit: &Commit{AuthorName: "mallory", AuthorEmail: "mallory@example.com"}, expected: false},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			if result := isCodeOwner(conf, c.owners, c.commit); result != c.expected {
+				t.Errorf("expected owner = %t, got %t", c.expected, result)
+			}
+		})
+	}
+
+	reason, flagged := codeOwnersReason(conf, owners, &Commit{AuthorName: "builder"})
+	if !flagged {
+		t.Fatalf("expected a reason")
+	}
+	expected := Reason{Rule: "not code owner", Match: "1 of 2 owned files, e.g. main.go (@org/core)", Contribution: 30}
+	if diff := cmp.Diff(reason, expected); diff != "" {
+		t.Errorf("unexpected reason: %s", diff)
+	}
+}
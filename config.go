@@ -7,13 +7,183 @@ import (
 )
 
 type Config struct {
-	CodeFolder string                   `toml:"code_folder"`
-	Projects   map[string]ConfigProject `toml:"project"`
+	CodeFolder   string                   `toml:"code_folder"`
+	Projects     map[string]ConfigProject `toml:"project"`
+	Profiles     map[string]ConfigProfile `toml:"profile"`
+	Rules        []ConfigRule             `toml:"rule"`
+	Patterns     []ConfigPattern          `toml:"pattern"`
+	Churn        ConfigChurn              `toml:"churn"`
+	Contributors ConfigContributors       `toml:"contributors"`
+	CodeOwners   ConfigCodeOwners         `toml:"codeowners"`
+	Signatures   ConfigSignatures         `toml:"signatures"`
+	Messages     ConfigMessages           `toml:"messages"`
+	BuildWeights map[string]int           `toml:"build_weights"`
+	APIWeights   map[string]int           `toml:"api_weights"`
+	Tests        ConfigTests              `toml:"tests"`
+	TreeWeights  map[string]int           `toml:"tree_weights"`
+	Learning     ConfigLearning           `toml:"learning"`
+	Scorers      []ConfigScorer           `toml:"scorer"`
+	Analyzers    []ConfigAnalyzer         `toml:"analyzer"`
+	Advisories   ConfigAdvisories         `toml:"advisories"`
+	Licenses     ConfigLicenses           `toml:"licenses"`
+	Typosquats   ConfigTyposquats         `toml:"typosquats"`
+	// weight per kind of license change (see LICENSE_ constants), overrides the defaults
+	LicenseWeights map[string]int `toml:"license_weights"`
+	// weight per kind of binary file (see BINARY_ constants), overrides the defaults
+	BinaryWeights map[string]int `toml:"binary_weights"`
+	// weight per dependency change kind (see DEP_ constants), overrides the defaults
+	DependencyWeights map[string]int `toml:"dependency_weights"`
 }
 
 type ConfigProject struct {
 	Icon string `toml:"icon"`
 	Repo string `toml:"repo"`
+	// used to verify commit signatures offline
+	AllowedSigners string `toml:"allowed_signers"` // ssh allowed signers file
+	GPGHome        string `toml:"gpg_home"`        // gpg home folder with the project's keyring
+	License        string `toml:"license"`         // SPDX identifier, identified from the license file if not set
+	// scoring profile of the project, the weights override the profile's
+	Profile string         `toml:"profile"`
+	Weights map[string]int `toml:"weights"`
+}
+
+// ConfigProfile is a named scoring profile projects can select. The weights
+// replace the contribution of the reasons with the rule as key, e.g. "build
+// ci" or "keyword revert", a weight of 0 drops the reasons.
+type ConfigProfile struct {
+	Aggregation string         `toml:"aggregation"` // capped_sum (default), weighted_sum or max
+	Decay       float64        `toml:"decay"`       // weighted_sum: factor per further reason, 0.5 if not set
+	Weights     map[string]int `toml:"weights"`
+}
+
+// ConfigRule describes a scoring rule. All matchers that are set must match
+// for the rule to trigger, the triggered rules' weights are summed up to the
+// slat score.
+type ConfigRule struct {
+	Name     string   `toml:"name"`
+	Weight   int      `toml:"weight"`
+	Paths    []string `toml:"paths"`    // globs, matched against every file in the diff
+	Messages []string `toml:"messages"` // regexes, matched against subject and body
+	Authors  []string `toml:"authors"`  // regexes, matched against author and committer name
+	MinChurn uint64   `toml:"min_churn"`
+	Files    string   `toml:"files"` // files paths and min_churn consider: all (default), handwritten, generated or vendored
+}
+
+// ConfigPattern is a regex that is matched against the lines added by a
+// commit. A pattern with the name of a built-in pattern replaces it, a weight
+// of 0 disables it.
+type ConfigPattern struct {
+	Name      string   `toml:"name"`
+	Languages []string `toml:"languages"` // empty for all files
+	Regex     string   `toml:"regex"`
+	Weight    int      `toml:"weight"`
+}
+
+// ConfigChurn configures how commits are scored that are unusually big
+// compared to the other commits of the project.
+type ConfigChurn struct {
+	Weight     int     `toml:"weight"`      // 0 disables the churn scoring
+	Threshold  float64 `toml:"threshold"`   // robust z-score above which a commit is an outlier
+	MinHistory int     `toml:"min_history"` // minimal number of stored commits for a baseline
+}
+
+// ConfigContributors configures the scoring of authors and committers that
+// never or rarely committed to the project before.
+type ConfigContributors struct {
+	NewWeight     int `toml:"new_weight"`
+	RareWeight    int `toml:"rare_weight"`
+	RareCommits   int `toml:"rare_commits"` // contributors with less earlier commits are rare
+	DormantWeight int `toml:"dormant_weight"`
+	DormantDays   int `toml:"dormant_days"` // days without a commit after which a contributor is dormant
+	MinHistory    int `toml:"min_history"`  // minimal number of stored commits of the project
+}
+
+// ConfigCodeOwners configures the scoring of changes to files the author
+// does not own according to the project's CODEOWNERS file.
+type ConfigCodeOwners struct {
+	Weight int                 `toml:"weight"`
+	Teams  map[string][]string `toml:"teams"` // members (@login or email) of the @org/team owners
+}
+
+// ConfigSignatures configures the scoring of commit signatures.
+type ConfigSignatures struct {
+	BadWeight      int     `toml:"bad_weight"`      // bad signatures or revoked keys
+	UnknownWeight  int     `toml:"unknown_weight"`  // unknown or expired keys, only if the project normally signs
+	UnsignedWeight int     `toml:"unsigned_weight"` // only if the project normally signs
+	MinRatio       float64 `toml:"min_ratio"`       // ratio of signed commits from which on a project normally signs
+	MinHistory     int     `toml:"min_history"`     // minimal number of stored commits of the project
+}
+
+// ConfigMessages configures the scoring of commit messages.
+type ConfigMessages struct {
+	ReferenceWeight int            `toml:"reference_weight"` // CVE and GHSA identifiers
+	Keywords        map[string]int `toml:"keywords"`         // weight per keyword, overrides the defaults
+}
+
+// ConfigTests configures the scoring of commits that change production code
+// without tests and of commits that remove tests. A threshold of 0 disables
+// the check.
+type ConfigTests struct {
+	MissingWeight   int `toml:"missing_weight"`
+	MinSourceLines  int `toml:"min_source_lines"` // changed lines of production code
+	DeletedWeight   int `toml:"deleted_weight"`
+	MinDeletedLines int `toml:"min_deleted_lines"` // removed test lines
+}
+
+// ConfigLearning configures how the weights of the rules are refitted per
+// project from the review verdicts.
+type ConfigLearning struct {
+	RefitDays int     `toml:"refit_days"` // days between automatic refits, 0 disables them
+	MinLabels int     `toml:"min_labels"` // labelled commits a rule must have fired on
+	MinFactor float64 `toml:"min_factor"`
+	MaxFactor float64 `toml:"max_factor"`
+}
+
+// ConfigScorer registers an external scorer executable. It gets the commit as
+// JSON on stdin and writes its score and reasons as JSON to stdout.
+type ConfigScorer struct {
+	Name     string   `toml:"name"`
+	Command  []string `toml:"command"`   // executable and arguments
+	Timeout  int      `toml:"timeout"`   // seconds, 10 if not set
+	Patch    bool     `toml:"patch"`     // send the added lines too
+	MaxScore int      `toml:"max_score"` // cap per reason, 100 if not set
+}
+
+// ConfigAnalyzer is a local command (go vet, staticcheck, a test command,
+// ...) that is run in a temporary worktree of the commit and of its parent.
+// Every output line is a finding, the ones the parent does not have
+// contribute to the score.
+type ConfigAnalyzer struct {
+	Name    string   `toml:"name"`
+	Command []string `toml:"command"` // executable and arguments, run in the worktree root
+	Timeout int      `toml:"timeout"` // seconds per run, 300 if not set
+	Paths   []string `toml:"paths"`   // globs, the analyzer only runs if the commit changes a matching file
+	Pattern string   `toml:"pattern"` // regex output lines must match to be a finding
+	Weight  int      `toml:"weight"`
+}
+
+// ConfigAdvisories configures the matching of dependency changes against a
+// local dump of OSV advisories (e.g. the unzipped osv.dev ecosystem exports or
+// a clone of github/advisory-database). The dump is read once per run.
+type ConfigAdvisories struct {
+	Dir              string `toml:"dir"`               // folder with the advisory .json files, empty disables the matching
+	VulnerableWeight int    `toml:"vulnerable_weight"` // a dependency is added or changed to an affected version
+	FixedWeight      int    `toml:"fixed_weight"`      // a dependency leaves an affected version
+}
+
+// ConfigLicenses configures the license checks.
+type ConfigLicenses struct {
+	Allowed  []string `toml:"allowed"`   // SPDX identifiers of dependency and header licenses that are never flagged
+	ModCache string   `toml:"mod_cache"` // Go module cache the licenses of dependencies are read from, the go command's if not set
+}
+
+// ConfigTyposquats configures the check of added go, cargo and npm
+// dependencies for names that look like existing or popular ones.
+type ConfigTyposquats struct {
+	Weight      int                 `toml:"weight"`
+	MaxDistance int                 `toml:"max_distance"` // edit distance of look-alikes, names of up to 5 characters need homoglyphs
+	Popular     map[string][]string `toml:"popular"`      // per ecosystem (Go, crates.io or npm), extends the built-in list
+	Allowed     []string            `toml:"allowed"`      // dependencies that are never flagged
 }
 
 // TODO Create config with project data and render them on the screen in the top bar
@@ -23,7 +193,24 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
-	var cfg Config
+	cfg := Config{
+		Churn: ConfigChurn{Weight: defaultChurnWeight, Threshold: defaultChurnThreshold, MinHistory: defaultChurnMinHistory},
+		Contributors: ConfigContributors{
+			NewWeight:     50,
+			RareWeight:    20,
+			RareCommits:   3,
+			DormantWeight: 40,
+			DormantDays:   180,
+			MinHistory:    50,
+		},
+		CodeOwners: ConfigCodeOwners{Weight: 30},
+		Signatures: ConfigSignatures{BadWeight: 100, UnknownWeight: 40, UnsignedWeight: 50, MinRatio: 0.8, MinHistory: 20},
+		Messages:   ConfigMessages{ReferenceWeight: 60},
+		Tests:      ConfigTests{MissingWeight: 30, MinSourceLines: 50, DeletedWeight: 40, MinDeletedLines: 20},
+		Learning:   ConfigLearning{RefitDays: 7, MinLabels: 5, MinFactor: 0.25, MaxFactor: 2},
+		Advisories: ConfigAdvisories{VulnerableWeight: 80, FixedWeight: 30},
+		Typosquats: ConfigTyposquats{Weight: maxSlatScore, MaxDistance: 2},
+	}
 	err = toml.Unmarshal(bytes, &cfg)
 	if err != nil {
 		return nil, err
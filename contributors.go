@@ -0,0 +1,129 @@
+package deckard
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	CONTRIBUTOR_NEW     = "new"
+	CONTRIBUTOR_RARE    = "rare"
+	CONTRIBUTOR_DORMANT = "dormant"
+)
+
+// contributorHistory knows when each author and committer of a project committed.
+type contributorHistory struct {
+	commits int                    // total number of known commits of the project
+	times   map[string][]time.Time // contributor name -> sorted commit times
+}
+
+func newContributorHistory() *contributorHistory {
+	return &contributorHistory{times: make(map[string][]time.Time)}
+}
+
+// add records a commit of the contributors. A name is only counted once per commit.
+func (h *contributorHistory) add(when time.Time, names ...string) {
+	h.commits++
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		times := h.times[name]
+		i := sort.Search(len(times), func(i int) bool { return times[i].After(when) })
+		times = append(times, time.Time{})
+		copy(times[i+1:], times[i:])
+		times[i] = when
+		h.times[name] = times
+	}
+}
+
+// before returns the number of commits of the contributor before t and the
+// time of the last of them.
+func (h *contributorHistory) before(name string, t time.Time) (int, time.Time) {
+	times := h.times[name]
+	n := sort.Search(len(times), func(i int) bool { return !times[i].Before(t) })
+	if n == 0 {
+		return 0, time.Time{}
+	}
+	return n, times[n-1]
+}
+
+// classify returns one of the CONTRIBUTOR_ constants, or "" for a regular contributor.
+func (h *contributorHistory) classify(conf ConfigContributors, name string, when time.Time) (string, string) {
+	count, last := h.before(name, when)
+	switch {
+	case count == 0:
+		return CONTRIBUTOR_NEW, "no earlier commit"
+	case conf.DormantDays > 0 && when.Sub(last) > time.Duration(conf.DormantDays)*24*time.Hour:
+		return CONTRIBUTOR_DORMANT, fmt.Sprintf("last commit %s", last.Format("2006-01-02"))
+	case count < conf.RareCommits:
+		return CONTRIBUTOR_RARE, fmt.Sprintf("%d earlier commits", count)
+	}
+	return "", ""
+}
+
+// contributorReasons classifies the author and the committer of the commit.
+// The returned class is the one of the author, or the committer if the
+// author is a regular contributor.
+func contributorReasons(h *contributorHistory, conf ConfigContributors, commit *Commit) (string, []Reason) {
+	reasons := make([]Reason, 0)
+	if h == nil || h.commits < conf.MinHistory {
+		return "", reasons // every contributor would be new
+	}
+
+	badge := ""
+	roles := []struct {
+		role string
+		name string
+	}{
+		{"author", commit.AuthorName},
+		{"committer", commit.CommitterName},
+	}
+	for i, role := range roles {
+		if i > 0 && role.name == roles[0].name {
+			continue
+		}
+		class, detail := h.classify(conf, role.name, commit.CommitWhen)
+		if class == "" {
+			continue
+		}
+		if badge == "" {
+			badge = class
+		}
+		if weight := conf.weight(class); weight > 0 {
+			reasons = append(reasons, Reason{
+				Rule:         fmt.Sprintf("%s %s", class, role.role),
+				Match:        fmt.Sprintf("%s (%s)", role.name, detail),
+				Contribution: weight,
+			})
+		}
+	}
+	return badge, reasons
+}
+
+func (conf ConfigContributors) weight(class string) int {
+	switch class {
+	case CONTRIBUTOR_NEW:
+		return conf.NewWeight
+	case CONTRIBUTOR_RARE:
+		return conf.RareWeight
+	case CONTRIBUTOR_DORMANT:
+		return conf.DormantWeight
+	}
+	return 0
+}
+
+func contributorBadge(class string) string {
+	switch class {
+	case CONTRIBUTOR_NEW:
+		return "🆕"
+	case CONTRIBUTOR_RARE:
+		return "🐣"
+	case CONTRIBUTOR_DORMANT:
+		return "💤"
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package deckard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestContributorReasons(t *testing.T) {
+	conf := ConfigContributors{NewWeight: 50, RareWeight: 20, RareCommits: 3, DormantWeight: 40, DormantDays: 180, MinHistory: 5}
+	day := func(d int) time.Time {
+		return time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(d) * 24 * time.Hour)
+	}
+
+	history := newContributorHistory()
+	for d := 1; d <= 10; d++ {
+		history.add(day(d), "regular", "GitHub")
+	}
+	history.add(day(2), "rare", "GitHub")
+	history.add(day(3), "sleeper", "sleeper")
+	history.add(day(4), "sleeper", "sleeper")
+	history.add(day(5), "sleeper", "sleeper")
+
+	tc := []struct {
+		desc            string
+		history         *contributorHistory
+		commit          *Commit
+		expectedBadge   string
+		expectedReasons []Reason
+	}{
+		{
+			desc:            "regular contributor",
+			history:         history,
+			commit:          &Commit{AuthorName: "regular", CommitterName: "GitHub", CommitWhen: day(11)},
+			expectedBadge:   "",
+			expectedReasons: []Reason{},
+		},
+		{
+			desc:          "first commit of the author",
+			history:       history,
+			commit:        &Commit{AuthorName: "newbie", CommitterName: "GitHub", CommitWhen: day(11)},
+			expectedBadge: CONTRIBUTOR_NEW,
+			expectedReasons: []Reason{
+				{Rule: "new author", Match: "newbie (no earlier commit)", Contribution: 50},
+			},
+		},
+		{
+			desc:          "commits after the scored commit do not count",
+			history:       history,
+			commit:        &Commit{AuthorName: "rare", CommitterName: "rare", CommitWhen: day(2)},
+			expectedBadge: CONTRIBUTOR_NEW,
+			expectedReasons: []Reason{
+				{Rule: "new author", Match: "rare (no earlier commit)", Contribution: 50},
+			},
+		},
+		{
+			desc:          "rare author",
+			history:       history,
+			commit:        &Commit{AuthorName: "rare", CommitterName: "GitHub", CommitWhen: day(11)},
+			expectedBadge: CONTRIBUTOR_RARE,
+			expectedReasons: []Reason{
+				{Rule: "rare author", Match: "rare (1 earlier commits)", Contribution: 20},
+			},
+		},
+		{
+			desc:          "dormant author and committer",
+			history:       history,
+			commit:        &Commit{AuthorName: "sleeper", CommitterName: "sleeper", CommitWhen: day(400)},
+			expectedBadge: CONTRIBUTOR_DORMANT,
+			expectedReasons: []Reason{
+				{Rule: "dormant author", Match: "sleeper (last commit 2022-01-06)", Contribution: 40},
+			},
+		},
+		{
+			desc:          "regular author but new committer",
+			history:       history,
+			commit:        &Commit{AuthorName: "regular", CommitterName: "intruder", CommitWhen: day(11)},
+			expectedBadge: CONTRIBUTOR_NEW,
+			expectedReasons: []Reason{
+				{Rule: "new committer", Match: "intruder (no earlier commit)", Contribution: 50},
+			},
+		},
+		{
+			desc:            "not enough history",
+			history:         newContributorHistory(),
+			commit:          &Commit{AuthorName: "newbie", CommitterName: "GitHub", CommitWhen: day(11)},
+			expectedBadge:   "",
+			expectedReasons: []Reason{},
+		},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			badge, reasons := contributorReasons(c.history, conf, c.commit)
+			if badge != c.expectedBadge {
+				t.Errorf("expected badge %s, got %s", c.expectedBadge, badge)
+			}
+			if diff := cmp.Diff(reasons, c.expectedReasons); diff != "" {
+				t.Errorf("unexpected reasons: %s", diff)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package deckard
 import (
 	"database/sql"
 	"path"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -22,6 +23,66 @@ var migrations = [][]string{
 		"CREATE TABLE IF NOT EXISTS commits (project TEXT NOT NULL, hash TEXT NOT NULL, message TEXT NOT NULL, author_name TEXT NOT NULL, committer_name TEXT NOT NULL, commit_when INTEGER, slat_score INTEGER, state TEXT NOT NULL, comment TEXT)",
 		"CREATE UNIQUE INDEX IF NOT EXISTS index_commits ON commits (project, hash)",
 	},
+	{
+		"CREATE TABLE IF NOT EXISTS slat_reasons (project TEXT NOT NULL, hash TEXT NOT NULL, rule TEXT NOT NULL, match TEXT NOT NULL, contribution INTEGER NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS index_slat_reasons ON slat_reasons (project, hash)",
+	},
+	{
+		"CREATE TABLE IF NOT EXISTS dependency_changes (project TEXT NOT NULL, hash TEXT NOT NULL, manifest TEXT NOT NULL, module TEXT NOT NULL, old_version TEXT NOT NULL, new_version TEXT NOT NULL, kind TEXT NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS index_dependency_changes ON dependency_changes (project, hash)",
+	},
+	{
+		"ALTER TABLE commits ADD COLUMN lines_changed INTEGER",
+		"ALTER TABLE commits ADD COLUMN files_changed INTEGER",
+	},
+	{
+		"ALTER TABLE commits ADD COLUMN contributor TEXT",
+	},
+	{
+		"ALTER TABLE commits ADD COLUMN author_email TEXT",
+		"ALTER TABLE commits ADD COLUMN committer_email TEXT",
+		"ALTER TABLE commits ADD COLUMN owners TEXT",
+	},
+	{
+		"ALTER TABLE commits ADD COLUMN signature TEXT",
+		"ALTER TABLE commits ADD COLUMN signing_key TEXT",
+	},
+	{
+		"CREATE TABLE IF NOT EXISTS commit_references (project TEXT NOT NULL, hash TEXT NOT NULL, kind TEXT NOT NULL, id TEXT NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS index_commit_references ON commit_references (project, hash)",
+	},
+	{
+		"CREATE TABLE IF NOT EXISTS api_changes (project TEXT NOT NULL, hash TEXT NOT NULL, package TEXT NOT NULL, name TEXT NOT NULL, kind TEXT NOT NULL, old TEXT NOT NULL, new TEXT NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS index_api_changes ON api_changes (project, hash)",
+	},
+	{
+		"ALTER TABLE commits ADD COLUMN tests TEXT",
+	},
+	{
+		"ALTER TABLE commits ADD COLUMN generated_lines INTEGER",
+		"ALTER TABLE commits ADD COLUMN vendored_lines INTEGER",
+	},
+	{
+		"ALTER TABLE commits ADD COLUMN verdict TEXT",
+		"CREATE TABLE IF NOT EXISTS weight_fit_runs (project TEXT NOT NULL, fitted_at INTEGER NOT NULL)",
+		"CREATE TABLE IF NOT EXISTS weight_fits (project TEXT NOT NULL, rule TEXT NOT NULL, old_factor REAL NOT NULL, new_factor REAL NOT NULL, interesting INTEGER NOT NULL, false_alarms INTEGER NOT NULL, fitted_at INTEGER NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS index_weight_fits ON weight_fits (project, fitted_at)",
+	},
+	{
+		"ALTER TABLE commits ADD COLUMN scoring_failed TEXT",
+	},
+	{
+		"CREATE TABLE IF NOT EXISTS vulnerability_matches (project TEXT NOT NULL, hash TEXT NOT NULL, manifest TEXT NOT NULL, module TEXT NOT NULL, version TEXT NOT NULL, advisory TEXT NOT NULL, kind TEXT NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS index_vulnerability_matches ON vulnerability_matches (project, hash)",
+	},
+	{
+		"CREATE TABLE IF NOT EXISTS license_changes (project TEXT NOT NULL, hash TEXT NOT NULL, kind TEXT NOT NULL, item TEXT NOT NULL, detail TEXT NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS index_license_changes ON license_changes (project, hash)",
+	},
+	{
+		"CREATE TABLE IF NOT EXISTS analyzer_findings (project TEXT NOT NULL, hash TEXT NOT NULL, analyzer TEXT NOT NULL, text TEXT NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS index_analyzer_findings ON analyzer_findings (project, hash)",
+	},
 }
 
 func InitDB(config *Config) (*sql.DB, error) {
@@ -102,8 +163,321 @@ func UpdateFetchState(db *sql.DB, project string, t *time.Time) error {
 
 func StoreCommits(db *sql.DB, commits []*Commit) error {
 	for _, commit := range commits {
-		_, err := db.Exec("INSERT OR IGNORE INTO commits (project, hash, message, author_name, committer_name, commit_when, slat_score, state, comment) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)",
-			commit.Project, commit.Hash, commit.Subject, commit.AuthorName, commit.CommitterName, commit.CommitWhen.UnixMilli(), commit.SlatScore, commit.State, commit.Comment)
+		res, err := db.Exec("INSERT OR IGNORE INTO commits (project, hash, message, author_name, committer_name, commit_when, slat_score, state, comment, lines_changed, files_changed, contributor, author_email, committer_email, owners, signature, signing_key, tests, generated_lines, vendored_lines, scoring_failed) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13, ?14, ?15, ?16, ?17, ?18, ?19, ?20, ?21)",
+			commit.Project, commit.Hash, commit.Subject, commit.AuthorName, commit.CommitterName, commit.CommitWhen.UnixMilli(), commit.SlatScore, commit.State, commit.Comment, commit.LinesChanged, commit.FilesChanged, commit.Contributor,
+			commit.AuthorEmail, commit.CommitterEmail, strings.Join(commit.Owners, " "), commit.Signature, commit.SigningKey, commit.Tests, commit.GeneratedLines, commit.VendoredLines, commit.ScoringFailed)
+		if err != nil {
+			return err
+		}
+		inserted, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if inserted == 0 { // commit already known, keep the stored reasons
+			continue
+		}
+		err = storeReasons(db, commit)
+		if err != nil {
+			return err
+		}
+		err = storeDependencies(db, commit)
+		if err != nil {
+			return err
+		}
+		err = storeReferences(db, commit)
+		if err != nil {
+			return err
+		}
+		err = storeAPIChanges(db, commit)
+		if err != nil {
+			return err
+		}
+		err = storeVulnerabilities(db, commit)
+		if err != nil {
+			return err
+		}
+		err = storeLicenseChanges(db, commit)
+		if err != nil {
+			return err
+		}
+		err = storeFindings(db, commit)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCommitHashes returns the hashes of all stored commits of the project, oldest first.
+func loadCommitHashes(db *sql.DB, project string) ([]string, error) {
+	rows, err := db.Query("SELECT hash FROM commits WHERE project = ?1 ORDER BY commit_when", project)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make([]string, 0)
+	var hash string
+	for rows.Next() {
+		err = rows.Scan(&hash)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+// updateAnalysis replaces the stored score, reasons and analysis results of
+// an already stored commit. The review state and comment are kept.
+func updateAnalysis(db *sql.DB, commit *Commit) error {
+	_, err := db.Exec("UPDATE commits SET message = ?3, author_name = ?4, committer_name = ?5, slat_score = ?6, lines_changed = ?7, files_changed = ?8, contributor = ?9, author_email = ?10, committer_email = ?11, owners = ?12, signature = ?13, signing_key = ?14, tests = ?15, generated_lines = ?16, vendored_lines = ?17, scoring_failed = ?18 WHERE project = ?1 AND hash = ?2",
+		commit.Project, commit.Hash, commit.Subject, commit.AuthorName, commit.CommitterName, commit.SlatScore, commit.LinesChanged, commit.FilesChanged, commit.Contributor,
+		commit.AuthorEmail, commit.CommitterEmail, strings.Join(commit.Owners, " "), commit.Signature, commit.SigningKey, commit.Tests, commit.GeneratedLines, commit.VendoredLines, commit.ScoringFailed)
+	if err != nil {
+		return err
+	}
+	for _, table := range []string{"slat_reasons", "dependency_changes", "commit_references", "api_changes", "vulnerability_matches", "license_changes", "analyzer_findings"} {
+		_, err = db.Exec("DELETE FROM "+table+" WHERE project = ?1 AND hash = ?2", commit.Project, commit.Hash)
+		if err != nil {
+			return err
+		}
+	}
+	err = storeReasons(db, commit)
+	if err != nil {
+		return err
+	}
+	err = storeDependencies(db, commit)
+	if err != nil {
+		return err
+	}
+	err = storeReferences(db, commit)
+	if err != nil {
+		return err
+	}
+	err = storeAPIChanges(db, commit)
+	if err != nil {
+		return err
+	}
+	err = storeVulnerabilities(db, commit)
+	if err != nil {
+		return err
+	}
+	err = storeLicenseChanges(db, commit)
+	if err != nil {
+		return err
+	}
+	return storeFindings(db, commit)
+}
+
+func storeReasons(db *sql.DB, commit *Commit) error {
+	for _, reason := range commit.Reasons {
+		_, err := db.Exec("INSERT INTO slat_reasons (project, hash, rule, match, contribution) VALUES (?1, ?2, ?3, ?4, ?5)",
+			commit.Project, commit.Hash, reason.Rule, reason.Match, reason.Contribution)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadReasons loads the slat reasons of all commits with the given state, keyed by commitKey.
+func loadReasons(db *sql.DB, state CommitState) (map[string][]Reason, error) {
+	rows, err := db.Query("SELECT r.project, r.hash, r.rule, r.match, r.contribution FROM slat_reasons r JOIN commits c ON r.project = c.project AND r.hash = c.hash WHERE c.state = ?1 ORDER BY r.rowid", state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reasons := make(map[string][]Reason)
+	var project string
+	var hash string
+	var reason Reason
+	for rows.Next() {
+		err = rows.Scan(&project, &hash, &reason.Rule, &reason.Match, &reason.Contribution)
+		if err != nil {
+			return nil, err
+		}
+		key := commitKey(project, hash)
+		reasons[key] = append(reasons[key], reason)
+	}
+	return reasons, rows.Err()
+}
+
+func storeDependencies(db *sql.DB, commit *Commit) error {
+	for _, dep := range commit.Dependencies {
+		_, err := db.Exec("INSERT INTO dependency_changes (project, hash, manifest, module, old_version, new_version, kind) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)",
+			commit.Project, commit.Hash, dep.Manifest, dep.Module, dep.OldVersion, dep.NewVersion, dep.Kind)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadDependencies loads the dependency changes of all commits with the given state, keyed by commitKey.
+func loadDependencies(db *sql.DB, state CommitState) (map[string][]DependencyChange, error) {
+	rows, err := db.Query("SELECT d.project, d.hash, d.manifest, d.module, d.old_version, d.new_version, d.kind FROM dependency_changes d JOIN commits c ON d.project = c.project AND d.hash = c.hash WHERE c.state = ?1 ORDER BY d.rowid", state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deps := make(map[string][]DependencyChange)
+	var project string
+	var hash string
+	var dep DependencyChange
+	for rows.Next() {
+		err = rows.Scan(&project, &hash, &dep.Manifest, &dep.Module, &dep.OldVersion, &dep.NewVersion, &dep.Kind)
+		if err != nil {
+			return nil, err
+		}
+		key := commitKey(project, hash)
+		deps[key] = append(deps[key], dep)
+	}
+	return deps, rows.Err()
+}
+
+func storeAPIChanges(db *sql.DB, commit *Commit) error {
+	for _, change := range commit.APIChanges {
+		_, err := db.Exec("INSERT INTO api_changes (project, hash, package, name, kind, old, new) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)",
+			commit.Project, commit.Hash, change.Package, change.Name, change.Kind, change.Old, change.New)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadAPIChanges loads the API changes of all commits with the given state, keyed by commitKey.
+func loadAPIChanges(db *sql.DB, state CommitState) (map[string][]APIChange, error) {
+	rows, err := db.Query("SELECT a.project, a.hash, a.package, a.name, a.kind, a.old, a.new FROM api_changes a JOIN commits c ON a.project = c.project AND a.hash = c.hash WHERE c.state = ?1 ORDER BY a.rowid", state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make(map[string][]APIChange)
+	var project string
+	var hash string
+	var change APIChange
+	for rows.Next() {
+		err = rows.Scan(&project, &hash, &change.Package, &change.Name, &change.Kind, &change.Old, &change.New)
+		if err != nil {
+			return nil, err
+		}
+		key := commitKey(project, hash)
+		changes[key] = append(changes[key], change)
+	}
+	return changes, rows.Err()
+}
+
+func storeVulnerabilities(db *sql.DB, commit *Commit) error {
+	for _, vuln := range commit.Vulnerabilities {
+		_, err := db.Exec("INSERT INTO vulnerability_matches (project, hash, manifest, module, version, advisory, kind) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)",
+			commit.Project, commit.Hash, vuln.Manifest, vuln.Module, vuln.Version, vuln.ID, vuln.Kind)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadVulnerabilities loads the vulnerability matches of all commits with the given state, keyed by commitKey.
+func loadVulnerabilities(db *sql.DB, state CommitState) (map[string][]VulnerabilityMatch, error) {
+	rows, err := db.Query("SELECT v.project, v.hash, v.manifest, v.module, v.version, v.advisory, v.kind FROM vulnerability_matches v JOIN commits c ON v.project = c.project AND v.hash = c.hash WHERE c.state = ?1 ORDER BY v.rowid", state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	vulns := make(map[string][]VulnerabilityMatch)
+	var project string
+	var hash string
+	var vuln VulnerabilityMatch
+	for rows.Next() {
+		err = rows.Scan(&project, &hash, &vuln.Manifest, &vuln.Module, &vuln.Version, &vuln.ID, &vuln.Kind)
+		if err != nil {
+			return nil, err
+		}
+		key := commitKey(project, hash)
+		vulns[key] = append(vulns[key], vuln)
+	}
+	return vulns, rows.Err()
+}
+
+func storeLicenseChanges(db *sql.DB, commit *Commit) error {
+	for _, change := range commit.Licenses {
+		_, err := db.Exec("INSERT INTO license_changes (project, hash, kind, item, detail) VALUES (?1, ?2, ?3, ?4, ?5)",
+			commit.Project, commit.Hash, change.Kind, change.Item, change.Detail)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadLicenseChanges loads the license changes of all commits with the given state, keyed by commitKey.
+func loadLicenseChanges(db *sql.DB, state CommitState) (map[string][]LicenseChange, error) {
+	rows, err := db.Query("SELECT l.project, l.hash, l.kind, l.item, l.detail FROM license_changes l JOIN commits c ON l.project = c.project AND l.hash = c.hash WHERE c.state = ?1 ORDER BY l.rowid", state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make(map[string][]LicenseChange)
+	var project string
+	var hash string
+	var change LicenseChange
+	for rows.Next() {
+		err = rows.Scan(&project, &hash, &change.Kind, &change.Item, &change.Detail)
+		if err != nil {
+			return nil, err
+		}
+		key := commitKey(project, hash)
+		changes[key] = append(changes[key], change)
+	}
+	return changes, rows.Err()
+}
+
+func storeFindings(db *sql.DB, commit *Commit) error {
+	for _, finding := range commit.Findings {
+		_, err := db.Exec("INSERT INTO analyzer_findings (project, hash, analyzer, text) VALUES (?1, ?2, ?3, ?4)",
+			commit.Project, commit.Hash, finding.Analyzer, finding.Text)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadFindings loads the analyzer findings of all commits with the given state, keyed by commitKey.
+func loadFindings(db *sql.DB, state CommitState) (map[string][]AnalyzerFinding, error) {
+	rows, err := db.Query("SELECT f.project, f.hash, f.analyzer, f.text FROM analyzer_findings f JOIN commits c ON f.project = c.project AND f.hash = c.hash WHERE c.state = ?1 ORDER BY f.rowid", state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	findings := make(map[string][]AnalyzerFinding)
+	var project string
+	var hash string
+	var finding AnalyzerFinding
+	for rows.Next() {
+		err = rows.Scan(&project, &hash, &finding.Analyzer, &finding.Text)
+		if err != nil {
+			return nil, err
+		}
+		key := commitKey(project, hash)
+		findings[key] = append(findings[key], finding)
+	}
+	return findings, rows.Err()
+}
+
+func storeReferences(db *sql.DB, commit *Commit) error {
+	for _, ref := range commit.References {
+		_, err := db.Exec("INSERT INTO commit_references (project, hash, kind, id) VALUES (?1, ?2, ?3, ?4)",
+			commit.Project, commit.Hash, ref.Kind, ref.ID)
 		if err != nil {
 			return err
 		}
@@ -111,6 +485,97 @@ func StoreCommits(db *sql.DB, commits []*Commit) error {
 	return nil
 }
 
+// loadReferences loads the advisory references of all commits with the given state, keyed by commitKey.
+func loadReferences(db *sql.DB, state CommitState) (map[string][]Reference, error) {
+	rows, err := db.Query("SELECT r.project, r.hash, r.kind, r.id FROM commit_references r JOIN commits c ON r.project = c.project AND r.hash = c.hash WHERE c.state = ?1 ORDER BY r.rowid", state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refs := make(map[string][]Reference)
+	var project string
+	var hash string
+	var ref Reference
+	for rows.Next() {
+		err = rows.Scan(&project, &hash, &ref.Kind, &ref.ID)
+		if err != nil {
+			return nil, err
+		}
+		key := commitKey(project, hash)
+		refs[key] = append(refs[key], ref)
+	}
+	return refs, rows.Err()
+}
+
+// loadChurnSamples loads the size of the most recent commits of a project.
+func loadChurnSamples(db *sql.DB, project string, limit int) ([]ChurnSample, error) {
+	rows, err := db.Query("SELECT lines_changed, files_changed FROM commits WHERE project = ?1 AND lines_changed IS NOT NULL ORDER BY commit_when DESC LIMIT ?2", project, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := make([]ChurnSample, 0)
+	var sample ChurnSample
+	for rows.Next() {
+		err = rows.Scan(&sample.Lines, &sample.Files)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+func loadContributorHistory(db *sql.DB, project string) (*contributorHistory, error) {
+	rows, err := db.Query("SELECT author_name, committer_name, commit_when FROM commits WHERE project = ?1", project)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := newContributorHistory()
+	var authorName string
+	var committerName string
+	var commitWhen int64
+	for rows.Next() {
+		err = rows.Scan(&authorName, &committerName, &commitWhen)
+		if err != nil {
+			return nil, err
+		}
+		history.add(time.UnixMilli(commitWhen), authorName, committerName)
+	}
+	return history, rows.Err()
+}
+
+func loadSignatureHistory(db *sql.DB, project string) (*signatureHistory, error) {
+	rows, err := db.Query("SELECT signature, COUNT(*) FROM commits WHERE project = ?1 AND signature IS NOT NULL AND signature != '' GROUP BY signature", project)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := &signatureHistory{}
+	var signature string
+	var count int
+	for rows.Next() {
+		err = rows.Scan(&signature, &count)
+		if err != nil {
+			return nil, err
+		}
+		history.total += count
+		if signature != SIGNATURE_NONE {
+			history.signed += count
+		}
+	}
+	return history, rows.Err()
+}
+
+func commitKey(project, hash string) string {
+	return project + "/" + hash
+}
+
 func UpdateCommitState(db *sql.DB, project, hash string, state CommitState) error {
 	_, err := db.Exec("UPDATE commits SET state = ?1 WHERE project = ?2 AND hash = ?3", state, project, hash)
 	if err != nil {
@@ -119,9 +584,144 @@ func UpdateCommitState(db *sql.DB, project, hash string, state CommitState) erro
 	return nil
 }
 
+// UpdateVerdict stores the review verdict of a commit and marks it as reviewed.
+func UpdateVerdict(db *sql.DB, project, hash, verdict string) error {
+	_, err := db.Exec("UPDATE commits SET state = ?1, verdict = ?2 WHERE project = ?3 AND hash = ?4", STATE_REVIEWED, verdict, project, hash)
+	return err
+}
+
+// loadLabelledCommits loads the commits of the project with a verdict and the rules that fired on them.
+func loadLabelledCommits(db *sql.DB, project string) ([]labelledCommit, error) {
+	rows, err := db.Query("SELECT c.hash, c.verdict, r.rule FROM commits c LEFT JOIN slat_reasons r ON r.project = c.project AND r.hash = c.hash WHERE c.project = ?1 AND c.verdict IS NOT NULL ORDER BY c.hash", project)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := make([]labelledCommit, 0)
+	lastHash := ""
+	var hash string
+	var verdict string
+	var rule sql.NullString
+	for rows.Next() {
+		err = rows.Scan(&hash, &verdict, &rule)
+		if err != nil {
+			return nil, err
+		}
+		if hash != lastHash {
+			labels = append(labels, labelledCommit{verdict: verdict})
+			lastHash = hash
+		}
+		if rule.Valid {
+			labels[len(labels)-1].rules = append(labels[len(labels)-1].rules, rule.String)
+		}
+	}
+	return labels, rows.Err()
+}
+
+// loadWeightFactors loads the latest fitted factor per rule of the project.
+func loadWeightFactors(db *sql.DB, project string) (map[string]float64, error) {
+	rows, err := db.Query("SELECT rule, new_factor FROM weight_fits WHERE project = ?1 ORDER BY fitted_at, rowid", project)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	factors := make(map[string]float64)
+	var rule string
+	var factor float64
+	for rows.Next() {
+		err = rows.Scan(&rule, &factor)
+		if err != nil {
+			return nil, err
+		}
+		factors[rule] = factor
+	}
+	return factors, rows.Err()
+}
+
+func storeWeightFits(db *sql.DB, project string, fits []WeightFit, when time.Time) error {
+	_, err := db.Exec("INSERT INTO weight_fit_runs (project, fitted_at) VALUES (?1, ?2)", project, when.UnixMilli())
+	if err != nil {
+		return err
+	}
+	for _, fit := range fits {
+		_, err = db.Exec("INSERT INTO weight_fits (project, rule, old_factor, new_factor, interesting, false_alarms, fitted_at) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)",
+			project, fit.Rule, fit.OldFactor, fit.NewFactor, fit.Interesting, fit.FalseAlarms, when.UnixMilli())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadWeightFits loads the fits of the project stored at the given time.
+func loadWeightFits(db *sql.DB, project string, when time.Time) ([]WeightFit, error) {
+	rows, err := db.Query("SELECT rule, old_factor, new_factor, interesting, false_alarms FROM weight_fits WHERE project = ?1 AND fitted_at = ?2 ORDER BY rule", project, when.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fits := make([]WeightFit, 0)
+	var fit WeightFit
+	for rows.Next() {
+		err = rows.Scan(&fit.Rule, &fit.OldFactor, &fit.NewFactor, &fit.Interesting, &fit.FalseAlarms)
+		if err != nil {
+			return nil, err
+		}
+		fits = append(fits, fit)
+	}
+	return fits, rows.Err()
+}
+
+// loadLastWeightFit returns when the weights of the project were fitted last, nil if never.
+func loadLastWeightFit(db *sql.DB, project string) (*time.Time, error) {
+	row := db.QueryRow("SELECT MAX(fitted_at) FROM weight_fit_runs WHERE project = ?1", project)
+	var last sql.NullInt64
+	err := row.Scan(&last)
+	if err != nil {
+		return nil, err
+	}
+	if !last.Valid {
+		return nil, nil
+	}
+	t := time.UnixMilli(last.Int64)
+	return &t, nil
+}
+
 func UpdateFromDB(db *sql.DB, ui *DeckardUI) error {
 
-	rows, err := db.Query("SELECT project, hash, message, author_name, committer_name, commit_when, slat_score, state, comment FROM commits WHERE state = ?1", STATE_NEW)
+	reasons, err := loadReasons(db, STATE_NEW)
+	if err != nil {
+		return err
+	}
+	deps, err := loadDependencies(db, STATE_NEW)
+	if err != nil {
+		return err
+	}
+	refs, err := loadReferences(db, STATE_NEW)
+	if err != nil {
+		return err
+	}
+	apiChanges, err := loadAPIChanges(db, STATE_NEW)
+	if err != nil {
+		return err
+	}
+	vulns, err := loadVulnerabilities(db, STATE_NEW)
+	if err != nil {
+		return err
+	}
+	licenses, err := loadLicenseChanges(db, STATE_NEW)
+	if err != nil {
+		return err
+	}
+	findings, err := loadFindings(db, STATE_NEW)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query("SELECT project, hash, message, author_name, committer_name, commit_when, slat_score, state, comment, contributor, author_email, committer_email, owners, signature, signing_key, tests, lines_changed, files_changed, generated_lines, vendored_lines, scoring_failed FROM commits WHERE state = ?1", STATE_NEW)
 	if err != nil {
 		return err
 	}
@@ -136,21 +736,52 @@ func UpdateFromDB(db *sql.DB, ui *DeckardUI) error {
 	var slatScore int
 	var state string
 	var comment *string
+	var contributor sql.NullString
+	var authorEmail sql.NullString
+	var committerEmail sql.NullString
+	var owners sql.NullString
+	var signature sql.NullString
+	var signingKey sql.NullString
+	var tests sql.NullString
+	var linesChanged sql.NullInt64
+	var filesChanged sql.NullInt64
+	var generatedLines sql.NullInt64
+	var vendoredLines sql.NullInt64
+	var scoringFailed sql.NullString
 	for rows.Next() {
-		err = rows.Scan(&project, &hash, &message, &authorName, &committerName, &commitWhen, &slatScore, &state, &comment)
+		err = rows.Scan(&project, &hash, &message, &authorName, &committerName, &commitWhen, &slatScore, &state, &comment, &contributor, &authorEmail, &committerEmail, &owners, &signature, &signingKey, &tests, &linesChanged, &filesChanged, &generatedLines, &vendoredLines, &scoringFailed)
 		if err != nil {
 			return err
 		}
 		commits = append(commits, &Commit{
-			Project:       project,
-			Hash:          hash,
-			Subject:       message,
-			AuthorName:    authorName,
-			CommitterName: committerName,
-			CommitWhen:    time.UnixMilli(commitWhen),
-			SlatScore:     slatScore,
-			State:         state,
-			Comment:       comment,
+			Project:         project,
+			Hash:            hash,
+			Subject:         message,
+			AuthorName:      authorName,
+			CommitterName:   committerName,
+			CommitWhen:      time.UnixMilli(commitWhen),
+			SlatScore:       slatScore,
+			State:           state,
+			Comment:         comment,
+			Reasons:         reasons[commitKey(project, hash)],
+			Dependencies:    deps[commitKey(project, hash)],
+			Contributor:     contributor.String,
+			AuthorEmail:     authorEmail.String,
+			CommitterEmail:  committerEmail.String,
+			Owners:          strings.Fields(owners.String),
+			Signature:       signature.String,
+			SigningKey:      signingKey.String,
+			References:      refs[commitKey(project, hash)],
+			APIChanges:      apiChanges[commitKey(project, hash)],
+			Vulnerabilities: vulns[commitKey(project, hash)],
+			Licenses:        licenses[commitKey(project, hash)],
+			Findings:        findings[commitKey(project, hash)],
+			Tests:           tests.String,
+			LinesChanged:    uint64(linesChanged.Int64),
+			FilesChanged:    uint64(filesChanged.Int64),
+			GeneratedLines:  uint64(generatedLines.Int64),
+			VendoredLines:   uint64(vendoredLines.Int64),
+			ScoringFailed:   scoringFailed.String,
 		})
 	}
 
@@ -0,0 +1,173 @@
+package deckard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	DEP_ADDED         = "added"
+	DEP_REMOVED       = "removed"
+	DEP_UPGRADED      = "upgraded"
+	DEP_MAJOR_UPGRADE = "major_upgrade"
+	DEP_DOWNGRADED    = "downgraded"
+	DEP_REPLACE       = "replace"
+	DEP_RETRACT       = "retract"
+	DEP_TOOLCHAIN     = "toolchain"
+
+	// not a kind of its own, scored additionally if a new version is a pseudo-version
+	DEP_PSEUDO_VERSION = "pseudo_version"
+)
+
+var defaultDependencyWeights = map[string]int{
+	DEP_ADDED:          50,
+	DEP_REMOVED:        10,
+	DEP_UPGRADED:       10,
+	DEP_MAJOR_UPGRADE:  60,
+	DEP_DOWNGRADED:     70,
+	DEP_REPLACE:        80,
+	DEP_RETRACT:        40,
+	DEP_TOOLCHAIN:      30,
+	DEP_PSEUDO_VERSION: 50,
+}
+
+// DependencyChange is a single change of a dependency manifest in a commit.
+type DependencyChange struct {
+	Manifest   string // path of the manifest file
+	Module     string
+	OldVersion string // empty if the dependency was added
+	NewVersion string // empty if the dependency was removed
+	Kind       string // one of the DEP_ constants
+}
+
+func (c DependencyChange) String() string {
+	switch {
+	case c.OldVersion == "":
+		return strings.TrimSpace(fmt.Sprintf("%s %s", c.Module, c.NewVersion))
+	case c.NewVersion == "":
+		return strings.TrimSpace(fmt.Sprintf("%s %s", c.Module, c.OldVersion))
+	default:
+		return fmt.Sprintf("%s %s → %s", c.Module, c.OldVersion, c.NewVersion)
+	}
+}
+
+// manifestAnalyzer finds the dependency changes of a manifest (or lock) file
+// of a package manager.
+type manifestAnalyzer interface {
+	// handles reports whether the analyzer understands the file
+	handles(file string) bool
+	// diff compares the manifest content before and after the commit, the
+	// content is empty if the file did not exist
+	diff(manifest, before, after string) ([]DependencyChange, error)
+	// ecosystem is the OSV ecosystem of the package manager
+	ecosystem() string
+}
+
+var manifestAnalyzers = []manifestAnalyzer{
+	goModAnalyzer{},
+	cargoAnalyzer{},
+	npmAnalyzer{},
+	pubAnalyzer{},
+	pythonAnalyzer{},
+	mavenAnalyzer{},
+}
+
+// dependencyChanges analyses all dependency manifests touched by the commit.
+// A manifest that does not parse (common in test fixtures) is skipped, the
+// failures are returned as text for the commit.
+func dependencyChanges(targetFolder, hash string, diff *Diff) ([]DependencyChange, string, error) {
+	changes := make([]DependencyChange, 0)
+	failures := make([]string, 0)
+	for _, stat := range diff.Stats {
+		if strings.Contains(stat.File, " => ") {
+			continue // renames are not analysed
+		}
+		analyzer := findManifestAnalyzer(stat.File)
+		if analyzer == nil {
+			continue
+		}
+
+		before, _, err := showFile(targetFolder, hash+"^", stat.File)
+		if err != nil {
+			return nil, "", err
+		}
+		after, _, err := showFile(targetFolder, hash, stat.File)
+		if err != nil {
+			return nil, "", err
+		}
+		fileChanges, err := analyzer.diff(stat.File, before, after)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", stat.File, err))
+			continue
+		}
+		changes = append(changes, fileChanges...)
+	}
+	return changes, strings.Join(failures, "; "), nil
+}
+
+func findManifestAnalyzer(file string) manifestAnalyzer {
+	for _, analyzer := range manifestAnalyzers {
+		if analyzer.handles(file) {
+			return analyzer
+		}
+	}
+	return nil
+}
+
+// diffVersions compares the dependency -> version maps of a manifest before
+// and after a commit.
+func diffVersions(manifest string, before, after map[string]string) []DependencyChange {
+	changes := make([]DependencyChange, 0)
+	for name, oldVersion := range before {
+		if _, ok := after[name]; !ok {
+			changes = append(changes, DependencyChange{Manifest: manifest, Module: name, OldVersion: oldVersion, Kind: DEP_REMOVED})
+		}
+	}
+	for name, newVersion := range after {
+		oldVersion, ok := before[name]
+		if !ok {
+			changes = append(changes, DependencyChange{Manifest: manifest, Module: name, NewVersion: newVersion, Kind: DEP_ADDED})
+			continue
+		}
+		if oldVersion == newVersion {
+			continue
+		}
+		changes = append(changes, DependencyChange{
+			Manifest:   manifest,
+			Module:     name,
+			OldVersion: oldVersion,
+			NewVersion: newVersion,
+			Kind:       versionChangeKind(oldVersion, newVersion),
+		})
+	}
+	sortDependencyChanges(changes)
+	return changes
+}
+
+// addVersion adds a version to the dependency map. Lock files may contain a
+// dependency in multiple versions, they are joined.
+func addVersion(versions map[string]string, name, version string) {
+	if existing, ok := versions[name]; ok && existing != version {
+		all := append(strings.Split(existing, ", "), version)
+		sort.Strings(all)
+		versions[name] = strings.Join(all, ", ")
+		return
+	}
+	versions[name] = version
+}
+
+func sortDependencyChanges(changes []DependencyChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Manifest != changes[j].Manifest {
+			return changes[i].Manifest < changes[j].Manifest
+		}
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		if changes[i].Module != changes[j].Module {
+			return changes[i].Module < changes[j].Module
+		}
+		return changes[i].NewVersion < changes[j].NewVersion
+	})
+}
@@ -0,0 +1,206 @@
+package deckard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestManifestAnalyzers(t *testing.T) {
+	tc := []struct {
+		desc     string
+		manifest string
+		before   string
+		after    string
+		expected []DependencyChange
+	}{
+		{
+			desc:     "Cargo.toml",
+			manifest: "Cargo.toml",
+			before:   "[package]\nname = \"app\"\n\n[dependencies]\nserde = \"1.0\"\nrand = { version = \"0.7\" }\n\n[dev-dependencies]\nold = \"1\"\n",
+			after:    "[package]\nname = \"app\"\n\n[dependencies]\nserde = \"1.0\"\nrand = { version = \"0.8\" }\nevil = { git = \"https://example.com/evil\", rev = \"abc\" }\n",
+			expected: []DependencyChange{
+				{Manifest: "Cargo.toml", Module: "evil", NewVersion: "git https://example.com/evil#abc", Kind: DEP_ADDED},
+				{Manifest: "Cargo.toml", Module: "old", OldVersion: "1", Kind: DEP_REMOVED},
+				{Manifest: "Cargo.toml", Module: "rand", OldVersion: "0.7", NewVersion: "0.8", Kind: DEP_UPGRADED},
+			},
+		},
+		{
+			desc:     "Cargo.lock",
+			manifest: "Cargo.lock",
+			before:   "[[package]]\nname = \"libc\"\nversion = \"0.2.1\"\n",
+			after:    "[[package]]\nname = \"libc\"\nversion = \"0.2.1\"\n\n[[package]]\nname = \"libc\"\nversion = \"1.0.0\"\n",
+			expected: []DependencyChange{
+				{Manifest: "Cargo.lock", Module: "libc", OldVersion: "0.2.1", NewVersion: "0.2.1, 1.0.0", Kind: DEP_UPGRADED},
+			},
+		},
+		{
+			desc:     "package.json",
+			manifest: "web/package.json",
+			before:   `{"dependencies": {"react": "^17.0.0"}, "devDependencies": {"jest": "^29.0.0"}}`,
+			after:    `{"dependencies": {"react": "^18.0.0", "left-pad": "1.3.0"}, "devDependencies": {"jest": "^28.0.0"}}`,
+			expected: []DependencyChange{
+				{Manifest: "web/package.json", Module: "left-pad", NewVersion: "1.3.0", Kind: DEP_ADDED},
+				{Manifest: "web/package.json", Module: "jest", OldVersion: "^29.0.0", NewVersion: "^28.0.0", Kind: DEP_DOWNGRADED},
+				{Manifest: "web/package.json", Module: "react", OldVersion: "^17.0.0", NewVersion: "^18.0.0", Kind: DEP_MAJOR_UPGRADE},
+			},
+		},
+		{
+			desc:     "package-lock.json",
+			manifest: "package-lock.json",
+			before:   `{"lockfileVersion": 3, "packages": {"": {"version": "1.0.0"}, "node_modules/a": {"version": "1.0.0"}}}`,
+			after:    `{"lockfileVersion": 3, "packages": {"": {"version": "1.0.0"}, "node_modules/a": {"version": "1.0.1"}, "node_modules/a/node_modules/@s/b": {"version": "2.0.0"}}}`,
+			expected: []DependencyChange{
+				{Manifest: "package-lock.json", Module: "@s/b", NewVersion: "2.0.0", Kind: DEP_ADDED},
+				{Manifest: "package-lock.json", Module: "a", OldVersion: "1.0.0", NewVersion: "1.0.1", Kind: DEP_UPGRADED},
+			},
+		},
+		{
+			desc:     "yarn.lock",
+			manifest: "yarn.lock",
+			before:   "# yarn lockfile v1\n\n\"@babel/core@^7.0.0\", \"@babel/core@^7.1.0\":\n  version \"7.2.0\"\n  resolved \"https://registry\"\n",
+			after:    "__metadata:\n  version: 6\n\n\"@babel/core@npm:^7.0.0\":\n  version: 7.3.0\n\nlodash@^4.0.0:\n  version: 4.17.21\n",
+			expected: []DependencyChange{
+				{Manifest: "yarn.lock", Module: "lodash", NewVersion: "4.17.21", Kind: DEP_ADDED},
+				{Manifest: "yarn.lock", Module: "@babel/core", OldVersion: "7.2.0", NewVersion: "7.3.0", Kind: DEP_UPGRADED},
+			},
+		},
+		{
+			desc:     "pubspec.yaml",
+			manifest: "packages/app/pubspec.yaml",
+			before:   "name: app\ndependencies:\n  flutter:\n    sdk: flutter\n  http: ^0.13.0 # comment\n",
+			after:    "name: app\ndependencies:\n  flutter:\n    sdk: flutter\n  http: ^1.0.0\n  fork:\n    git:\n      url: https://example.com/fork.git\n      ref: main\ndev_dependencies:\n  lints: ^2.0.0\n",
+			expected: []DependencyChange{
+				{Manifest: "packages/app/pubspec.yaml", Module: "fork", NewVersion: "git https://example.com/fork.git#main", Kind: DEP_ADDED},
+				{Manifest: "packages/app/pubspec.yaml", Module: "lints", NewVersion: "^2.0.0", Kind: DEP_ADDED},
+				{Manifest: "packages/app/pubspec.yaml", Module: "http", OldVersion: "^0.13.0", NewVersion: "^1.0.0", Kind: DEP_MAJOR_UPGRADE},
+			},
+		},
+		{
+			desc:     "pubspec.lock",
+			manifest: "pubspec.lock",
+			before:   "packages:\n  async:\n    dependency: transitive\n    description:\n      name: async\n    source: hosted\n    version: \"2.10.0\"\nsdks:\n  dart: \">=2.18.0 <3.0.0\"\n",
+			after:    "packages:\n  async:\n    dependency: transitive\n    description:\n      name: async\n    source: hosted\n    version: \"2.11.0\"\nsdks:\n  dart: \">=2.18.0 <3.0.0\"\n",
+			expected: []DependencyChange{
+				{Manifest: "pubspec.lock", Module: "async", OldVersion: "2.10.0", NewVersion: "2.11.0", Kind: DEP_UPGRADED},
+			},
+		},
+		{
+			desc:     "requirements.txt",
+			manifest: "requirements-dev.txt",
+			before:   "-r requirements.txt\nRequests==2.28.0\nflask>=2.0 ; python_version > '3.7'\n",
+			after:    "-r requirements.txt\nrequests==2.27.0 # pinned\nflask>=2.0 ; python_version > '3.7'\nnumpy[extra]~=1.24\n",
+			expected: []DependencyChange{
+				{Manifest: "requirements-dev.txt", Module: "numpy", NewVersion: "~=1.24", Kind: DEP_ADDED},
+				{Manifest: "requirements-dev.txt", Module: "requests", OldVersion: "==2.28.0", NewVersion: "==2.27.0", Kind: DEP_DOWNGRADED},
+			},
+		},
+		{
+			desc:     "pyproject.toml",
+			manifest: "pyproject.toml",
+			before:   "[tool.poetry.dependencies]\npython = \"^3.9\"\nDjango = \"^4.0\"\n",
+			after:    "[project]\ndependencies = [\"attrs>=22.1\"]\n\n[tool.poetry.dependencies]\npython = \"^3.10\"\nDjango = { version = \"^4.1\" }\n\n[tool.poetry.group.dev.dependencies]\npytest = \"^7.0\"\n",
+			expected: []DependencyChange{
+				{Manifest: "pyproject.toml", Module: "attrs", NewVersion: ">=22.1", Kind: DEP_ADDED},
+				{Manifest: "pyproject.toml", Module: "pytest", NewVersion: "^7.0", Kind: DEP_ADDED},
+				{Manifest: "pyproject.toml", Module: "django", OldVersion: "^4.0", NewVersion: "^4.1", Kind: DEP_UPGRADED},
+			},
+		},
+		{
+			desc:     "poetry.lock",
+			manifest: "poetry.lock",
+			before:   "[[package]]\nname = \"Jinja2\"\nversion = \"3.1.2\"\n",
+			after:    "",
+			expected: []DependencyChange{
+				{Manifest: "poetry.lock", Module: "jinja2", OldVersion: "3.1.2", Kind: DEP_REMOVED},
+			},
+		},
+		{
+			desc:     "pom.xml",
+			manifest: "pom.xml",
+			before: `<project><version>1.0</version><properties><junit.version>4.12</junit.version></properties>
+				<dependencies><dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>${junit.version}</version></dependency></dependencies></project>`,
+			after: `<project><version>1.0</version><properties><junit.version>4.13.2</junit.version></properties>
+				<dependencies><dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>${junit.version}</version></dependency>
+				<dependency><groupId>org.example</groupId><artifactId>util</artifactId><version>${project.version}</version></dependency></dependencies>
+				<build><plugins><plugin><artifactId>maven-shade-plugin</artifactId><version>3.4.1</version></plugin></plugins></build></project>`,
+			expected: []DependencyChange{
+				{Manifest: "pom.xml", Module: "org.apache.maven.plugins:maven-shade-plugin", NewVersion: "3.4.1", Kind: DEP_ADDED},
+				{Manifest: "pom.xml", Module: "org.example:util", NewVersion: "1.0", Kind: DEP_ADDED},
+				{Manifest: "pom.xml", Module: "junit:junit", OldVersion: "4.12", NewVersion: "4.13.2", Kind: DEP_UPGRADED},
+			},
+		},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			analyzer := findManifestAnalyzer(c.manifest)
+			if analyzer == nil {
+				t.Fatalf("no analyzer for %s", c.manifest)
+			}
+			changes, err := analyzer.diff(c.manifest, c.before, c.after)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if diff := cmp.Diff(changes, c.expected); diff != "" {
+				t.Errorf("unexpected changes: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDependencyChangesMalformedManifest(t *testing.T) {
+	folder := newTestRepo(t)
+	commitFiles(t, folder, map[string]string{
+		"go.mod":                   "module example.com/app\n\nrequire github.com/foo/bar v1.0.0\n",
+		"testdata/package.json":    `{"dependencies": {"react": `,
+		"testdata/pyproject.toml":  "[project\n",
+		"testdata/fixture/pom.xml": "<project><dependencies>",
+	})
+
+	diff := &Diff{Stats: []NumStat{
+		{File: "go.mod", Added: 3},
+		{File: "testdata/fixture/pom.xml", Added: 1},
+		{File: "testdata/package.json", Added: 1},
+		{File: "testdata/pyproject.toml", Added: 1},
+	}}
+	changes, failures, err := dependencyChanges(folder, "HEAD", diff)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	expected := []DependencyChange{
+		{Manifest: "go.mod", Module: "github.com/foo/bar", NewVersion: "v1.0.0", Kind: DEP_ADDED},
+	}
+	if diff := cmp.Diff(changes, expected); diff != "" {
+		t.Errorf("unexpected changes: %s", diff)
+	}
+	for _, manifest := range []string{"testdata/fixture/pom.xml", "testdata/package.json", "testdata/pyproject.toml"} {
+		if !strings.Contains(failures, manifest+": ") {
+			t.Errorf("expected a failure for %s, got %s", manifest, failures)
+		}
+	}
+}
+
+func TestFindManifestAnalyzer(t *testing.T) {
+	tc := []struct {
+		file     string
+		expected bool
+	}{
+		{"go.mod", true},
+		{"go.sum", false},
+		{"sub/Cargo.lock", true},
+		{"node_modules/x/package.json", false},
+		{"requirements/base.txt", true},
+		{"docs/notes.txt", false},
+		{"main.go", false},
+	}
+
+	for _, c := range tc {
+		t.Run(c.file, func(t *testing.T) {
+			if found := findManifestAnalyzer(c.file) != nil; found != c.expected {
+				t.Errorf("expected %t, got %t", c.expected, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package deckard
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	FILE_HANDWRITTEN = "handwritten"
+	FILE_GENERATED   = "generated" // generated code and lock files
+	FILE_VENDORED    = "vendored"  // copies of third party code
+)
+
+// commits with more changed files are only classified by path
+const maxGeneratedHeaderReads = 200
+
+// number of bytes read to find a generated code header
+const generatedHeaderSize = 1024
+
+var (
+	vendoredFolder     = regexp.MustCompile(`(^|/)(vendor|third_party|third-party|node_modules|bower_components)/`)
+	generatedFileNames = regexp.MustCompile(`(^|/)(go\.sum|go\.work\.sum|Cargo\.lock|package-lock\.json|npm-shrinkwrap\.json|yarn\.lock|pnpm-lock\.yaml|poetry\.lock|Pipfile\.lock|pubspec\.lock|Gemfile\.lock|composer\.lock|flake\.lock)$`)
+	generatedFiles     = regexp.MustCompile(`\.pb(\.gw)?\.go$|\.pb\.(cc|h)$|_pb2(_grpc)?\.pyi?$|_grpc\.pb\.go$|(^|/)zz_generated[^/]*$|[._]gen(erated)?\.go$|\.min\.(js|css)$|\.g\.dart$|\.freezed\.dart$`)
+	generatedHeader    = regexp.MustCompile(`(?mi)^\W*((code )?generated\b.*\bdo not edit|@generated\b|auto-?generated\b.*\bdo not (edit|modify))`)
+)
+
+// pathFileClass classifies a file by its path only, handwritten if the path
+// does not tell.
+func pathFileClass(file string) string {
+	if vendoredFolder.MatchString(file) {
+		return FILE_VENDORED
+	}
+	if generatedFileNames.MatchString(file) || generatedFiles.MatchString(file) {
+		return FILE_GENERATED
+	}
+	return FILE_HANDWRITTEN
+}
+
+// fileClasses returns the class of every changed file that is not
+// handwritten, keyed by the file name as in the numstat. Files are classified
+// by path and by a generated code header at the commit or, for deleted files,
+// at the parent.
+func fileClasses(targetFolder, hash string, diff *Diff) (map[string]string, error) {
+	classes := make(map[string]string)
+	reads := 0
+	for _, stat := range diff.Stats {
+		file := newFileName(stat.File)
+		class := pathFileClass(file)
+		if class == FILE_HANDWRITTEN && !stat.Binary && reads < maxGeneratedHeaderReads {
+			reads++
+			head, found, err := headFile(targetFolder, hash, file, generatedHeaderSize)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				head, _, err = headFile(targetFolder, hash+"^", oldFileName(stat.File), generatedHeaderSize)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if generatedHeader.Match(head) {
+				class = FILE_GENERATED
+			}
+		}
+		if class != FILE_HANDWRITTEN {
+			classes[stat.File] = class
+		}
+	}
+	return classes, nil
+}
+
+// fileClass returns the class of a numstat file.
+func (d *Diff) fileClass(file string) string {
+	if class, ok := d.Classes[file]; ok {
+		return class
+	}
+	return FILE_HANDWRITTEN
+}
+
+// classChurn sums up the changed lines per file class.
+func classChurn(diff *Diff) map[string]uint64 {
+	churn := make(map[string]uint64)
+	for _, stat := range diff.Stats {
+		churn[diff.fileClass(stat.File)] += stat.Added + stat.Deleted
+	}
+	return churn
+}
+
+// churnText describes the size of a commit with the generated and vendored
+// part, e.g. "120 lines in 4 files (80 generated)".
+func churnText(commit *Commit) string {
+	text := fmt.Sprintf("%d lines in %d files", commit.LinesChanged, commit.FilesChanged)
+	switch {
+	case commit.GeneratedLines > 0 && commit.VendoredLines > 0:
+		text += fmt.Sprintf(" (%d generated, %d vendored)", commit.GeneratedLines, commit.VendoredLines)
+	case commit.GeneratedLines > 0:
+		text += fmt.Sprintf(" (%d generated)", commit.GeneratedLines)
+	case commit.VendoredLines > 0:
+		text += fmt.Sprintf(" (%d vendored)", commit.VendoredLines)
+	}
+	return text
+}
@@ -0,0 +1,129 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPathFileClass(t *testing.T) {
+	tc := []struct {
+		file     string
+		expected string
+	}{
+		{file: "auth/login.go", expected: FILE_HANDWRITTEN},
+		{file: "api/v1/service.pb.go", expected: FILE_GENERATED},
+		{file: "api/v1/service_grpc.pb.go", expected: FILE_GENERATED},
+		{file: "proto/service_pb2.py", expected: FILE_GENERATED},
+		{file: "pkg/apis/zz_generated.deepcopy.go", expected: FILE_GENERATED},
+		{file: "go.sum", expected: FILE_GENERATED},
+		{file: "web/yarn.lock", expected: FILE_GENERATED},
+		{file: "static/app.min.js", expected: FILE_GENERATED},
+		{file: "lib/model.g.dart", expected: FILE_GENERATED},
+		{file: "vendor/golang.org/x/net/http2/frame.go", expected: FILE_VENDORED},
+		{file: "third_party/zlib/inflate.c", expected: FILE_VENDORED},
+		{file: "web/node_modules/left-pad/index.js", expected: FILE_VENDORED},
+		{file: "vendors.go", expected: FILE_HANDWRITTEN},
+	}
+
+	for _, c := range tc {
+		t.Run(c.file, func(t *testing.T) {
+			if class := pathFileClass(c.file); class != c.expected {
+				t.Errorf("expected class '%s', got '%s'", c.expected, class)
+			}
+		})
+	}
+}
+
+func TestGeneratedHeader(t *testing.T) {
+	tc := []struct {
+		desc     string
+		head     string
+		expected bool
+	}{
+		{desc: "go", head: "// Code generated by protoc-gen-go. DO NOT EDIT.\n// versions:\n\npackage api\n", expected: true},
+		{desc: "go after license", head: "// Copyright 2024\n\n// Code generated by stringer -type=Kind; DO NOT EDIT.\n", expected: true},
+		{desc: "python", head: "# -*- coding: utf-8 -*-\n# Generated by the protocol buffer compiler.  DO NOT EDIT!\n", expected: true},
+		{desc: "at generated", head: "/**\n * @generated SignedSource<<abc>>\n */\n", expected: true},
+		{desc: "auto generated", head: "<!-- Auto-generated file, do not edit -->\n", expected: true},
+		{desc: "handwritten", head: "package auth\n\n// generated tokens expire after an hour\n", expected: false},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			if generatedHeader.MatchString(c.head) != c.expected {
+				t.Errorf("expected match = %t", c.expected)
+			}
+		})
+	}
+}
+
+func TestClassChurn(t *testing.T) {
+	diff := &Diff{
+		Stats: []NumStat{
+			{File: "auth/login.go", Added: 10, Deleted: 2},
+			{File: "api/service.pb.go", Added: 300, Deleted: 200},
+			{File: "api/gen.go", Added: 20},
+			{File: "vendor/x/y.go", Added: 5},
+		},
+		Classes: map[string]string{"api/service.pb.go": FILE_GENERATED, "api/gen.go": FILE_GENERATED, "vendor/x/y.go": FILE_VENDORED},
+	}
+	expected := map[string]uint64{FILE_HANDWRITTEN: 12, FILE_GENERATED: 520, FILE_VENDORED: 5}
+	if diff := cmp.Diff(classChurn(diff), expected); diff != "" {
+		t.Errorf("unexpected churn: %s", diff)
+	}
+
+	commit := &Commit{LinesChanged: 537, FilesChanged: 4, GeneratedLines: 520, VendoredLines: 5}
+	if text := churnText(commit); text != "537 lines in 4 files (520 generated, 5 vendored)" {
+		t.Errorf("unexpected churn text: %s", text)
+	}
+}
+
+func TestRuleFiles(t *testing.T) {
+	rules := []ConfigRule{
+		{Name: "handwritten api", Weight: 40, Paths: []string{"api/**"}, Files: FILE_HANDWRITTEN},
+		{Name: "big vendor update", Weight: 30, MinChurn: 100, Files: FILE_VENDORED},
+	}
+	scorer, err := newSlatScorer(&Config{Rules: rules})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	tc := []struct {
+		desc            string
+		diff            *Diff
+		expectedReasons []Reason
+	}{
+		{
+			desc: "regenerated code only",
+			diff: &Diff{
+				Stats:   []NumStat{{File: "api/service.pb.go", Added: 300, Deleted: 200}},
+				Classes: map[string]string{"api/service.pb.go": FILE_GENERATED},
+			},
+			expectedReasons: []Reason{},
+		},
+		{
+			desc: "handwritten and vendored changes",
+			diff: &Diff{
+				Stats:   []NumStat{{File: "api/service.pb.go", Added: 300}, {File: "api/auth.go", Added: 3}, {File: "vendor/x/y.go", Added: 100}},
+				Classes: map[string]string{"api/service.pb.go": FILE_GENERATED, "vendor/x/y.go": FILE_VENDORED},
+			},
+			expectedReasons: []Reason{
+				{Rule: "handwritten api", Match: "api/auth.go", Contribution: 40},
+				{Rule: "big vendor update", Match: "100 lines changed", Contribution: 30},
+			},
+		},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			_, reasons, err := scorer.slatScore(&projectHistory{}, &Commit{Subject: "update"}, c.diff)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if diff := cmp.Diff(reasons, c.expectedReasons); diff != "" {
+				t.Errorf("unexpected reasons: %s", diff)
+			}
+		})
+	}
+}
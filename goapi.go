@@ -0,0 +1,387 @@
+package deckard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	API_REMOVED          = "removed"
+	API_SIGNATURE        = "signature"        // function or method signature changed
+	API_CHANGED          = "changed"          // type of a type, variable, constant or field changed
+	API_INTERFACE_METHOD = "interface_method" // method added to an exported interface
+)
+
+// weights used if not overwritten in the config
+var defaultAPIWeights = map[string]int{
+	API_REMOVED:          50,
+	API_SIGNATURE:        40,
+	API_CHANGED:          40,
+	API_INTERFACE_METHOD: 40,
+}
+
+// commits changing more packages are only analysed partially
+const maxAPIPackages = 20
+
+// APIChange is a breaking change of the exported API of a Go package.
+type APIChange struct {
+	Package string // folder of the package, the package name for the root folder
+	Name    string // identifier, Type.Method or Type.Field
+	Kind    string // one of the API_ constants
+	Old     string
+	New     string
+}
+
+func (c APIChange) String() string {
+	switch c.Kind {
+	case API_REMOVED:
+		return fmt.Sprintf("%s.%s removed", c.Package, c.Name)
+	case API_INTERFACE_METHOD:
+		return fmt.Sprintf("%s.%s added %s", c.Package, c.Name, c.New)
+	}
+	return fmt.Sprintf("%s.%s %s → %s", c.Package, c.Name, c.Old, c.New)
+}
+
+// apiSymbol is an exported part of a package API.
+type apiSymbol struct {
+	kind string // func, method, imethod, type, field, var or const
+	desc string // the type
+}
+
+// goAPIChanges compares the exported API of the Go packages changed by the commit.
+func goAPIChanges(targetFolder, hash string, diff *Diff) ([]APIChange, error) {
+	changes := make([]APIChange, 0)
+	for _, dir := range goPackageDirs(diff) {
+		beforeName, before, err := gitPackageAPI(targetFolder, hash+"^", dir)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil {
+			continue // new package
+		}
+		_, after, err := gitPackageAPI(targetFolder, hash, dir)
+		if err != nil {
+			return nil, err
+		}
+		pkg := dir
+		if dir == "." {
+			pkg = beforeName
+		}
+		changes = append(changes, diffAPI(pkg, before, after)...)
+	}
+	return changes, nil
+}
+
+// goPackageDirs returns the folders of importable packages with changed Go files.
+func goPackageDirs(diff *Diff) []string {
+	seen := make(map[string]bool)
+	dirs := make([]string, 0)
+	for _, stat := range diff.Stats {
+		for _, file := range []string{oldFileName(stat.File), newFileName(stat.File)} {
+			if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			dir := path.Dir(file)
+			if seen[dir] || !publicGoDir(dir) {
+				continue
+			}
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	if len(dirs) > maxAPIPackages {
+		dirs = dirs[:maxAPIPackages]
+	}
+	return dirs
+}
+
+// publicGoDir is false for folders the go tool ignores or that cannot be
+// imported by other modules.
+func publicGoDir(dir string) bool {
+	if dir == "." {
+		return true
+	}
+	for _, segment := range strings.Split(dir, "/") {
+		if segment == "internal" || segment == "testdata" || segment == "vendor" || strings.HasPrefix(segment, ".") || strings.HasPrefix(segment, "_") {
+			return false
+		}
+	}
+	return true
+}
+
+// gitPackageAPI returns the API of the package in dir at revision rev, nil if
+// there is no importable package.
+func gitPackageAPI(targetFolder, rev, dir string) (string, map[string]apiSymbol, error) {
+	files, err := listFiles(targetFolder, rev, dir)
+	if err != nil {
+		return "", nil, err
+	}
+	sources := make(map[string]string)
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		content, found, err := showFile(targetFolder, rev, file)
+		if err != nil {
+			return "", nil, err
+		}
+		if found {
+			sources[file] = content
+		}
+	}
+	name, api := packageAPI(dir, sources)
+	return name, api, nil
+}
+
+// packageAPI type checks the sources of a package and returns its exported
+// API, nil if the sources contain no importable package. Imported packages
+// are not resolved, every name selected from them is an opaque type.
+func packageAPI(dir string, sources map[string]string) (string, map[string]apiSymbol) {
+	fset := token.NewFileSet()
+	byName := make(map[string][]*ast.File)
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		file, err := parser.ParseFile(fset, name, sources[name], 0)
+		if err != nil {
+			continue
+		}
+		byName[file.Name.Name] = append(byName[file.Name.Name], file)
+	}
+
+	// files of other packages (e.g. ignored by a build tag) must not break the check
+	pkgName := ""
+	for name, files := range byName {
+		if name == "main" || strings.HasSuffix(name, "_test") {
+			continue
+		}
+		if pkgName == "" || len(files) > len(byName[pkgName]) || (len(files) == len(byName[pkgName]) && name < pkgName) {
+			pkgName = name
+		}
+	}
+	if pkgName == "" {
+		return "", nil
+	}
+
+	files := byName[pkgName]
+	conf := types.Config{
+		Importer:    newOpaqueImporter(files),
+		Error:       func(err error) {}, // build tags and unresolved imports cause errors
+		FakeImportC: true,
+	}
+	pkg, _ := conf.Check(dir, fset, files, nil)
+	return pkgName, exportedAPI(pkg)
+}
+
+func exportedAPI(pkg *types.Package) map[string]apiSymbol {
+	api := make(map[string]apiSymbol)
+	qualifier := types.RelativeTo(pkg)
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		switch obj := obj.(type) {
+		case *types.Func:
+			api[name] = apiSymbol{kind: "func", desc: types.TypeString(obj.Type(), qualifier)}
+		case *types.Var:
+			api[name] = apiSymbol{kind: "var", desc: types.TypeString(obj.Type(), qualifier)}
+		case *types.Const:
+			api[name] = apiSymbol{kind: "const", desc: types.TypeString(obj.Type(), qualifier)}
+		case *types.TypeName:
+			if obj.IsAlias() {
+				api[name] = apiSymbol{kind: "type", desc: "= " + types.TypeString(obj.Type(), qualifier)}
+				continue
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			switch underlying := named.Underlying().(type) {
+			case *types.Struct:
+				api[name] = apiSymbol{kind: "type", desc: "struct"}
+				for i := 0; i < underlying.NumFields(); i++ {
+					field := underlying.Field(i)
+					if field.Exported() {
+						api[name+"."+field.Name()] = apiSymbol{kind: "field", desc: types.TypeString(field.Type(), qualifier)}
+					}
+				}
+			case *types.Interface:
+				api[name] = apiSymbol{kind: "type", desc: "interface"}
+				// unexported methods count too, other packages cannot implement them
+				for i := 0; i < underlying.NumMethods(); i++ {
+					method := underlying.Method(i)
+					api[name+"."+method.Name()] = apiSymbol{kind: "imethod", desc: types.TypeString(method.Type(), qualifier)}
+				}
+				continue
+			default:
+				api[name] = apiSymbol{kind: "type", desc: types.TypeString(underlying, qualifier)}
+			}
+			methods := types.NewMethodSet(types.NewPointer(named))
+			for i := 0; i < methods.Len(); i++ {
+				method := methods.At(i).Obj()
+				if method.Exported() {
+					api[name+"."+method.Name()] = apiSymbol{kind: "method", desc: types.TypeString(method.Type(), qualifier)}
+				}
+			}
+		}
+	}
+	return api
+}
+
+// diffAPI returns the breaking changes between two versions of a package API.
+// after is nil if the package was removed.
+func diffAPI(pkg string, before, after map[string]apiSymbol) []APIChange {
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]APIChange, 0)
+	for _, name := range names {
+		old, inBefore := before[name]
+		new, inAfter := after[name]
+		switch {
+		case inBefore && !inAfter:
+			changes = append(changes, APIChange{Package: pkg, Name: name, Kind: API_REMOVED, Old: old.desc})
+		case !inBefore && new.kind == "imethod":
+			iface := name[:strings.Index(name, ".")]
+			if before[iface].desc == "interface" {
+				changes = append(changes, APIChange{Package: pkg, Name: name, Kind: API_INTERFACE_METHOD, New: new.desc})
+			}
+		case inBefore && old.desc != new.desc:
+			kind := API_CHANGED
+			if old.kind == new.kind && (old.kind == "func" || old.kind == "method" || old.kind == "imethod") {
+				kind = API_SIGNATURE
+			}
+			changes = append(changes, APIChange{Package: pkg, Name: name, Kind: kind, Old: old.desc, New: new.desc})
+		}
+	}
+	return changes
+}
+
+// opaqueImporter resolves imports to fake packages that declare every name
+// the files select from them as a type.
+type opaqueImporter struct {
+	names    map[string]string          // import path -> guessed package name
+	selected map[string]map[string]bool // import path -> selected names
+	packages map[string]*types.Package
+}
+
+func newOpaqueImporter(files []*ast.File) *opaqueImporter {
+	imp := &opaqueImporter{
+		names:    make(map[string]string),
+		selected: make(map[string]map[string]bool),
+		packages: make(map[string]*types.Package),
+	}
+	for _, file := range files {
+		local := make(map[string]string) // name in the file -> import path
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			name := guessPackageName(importPath)
+			imp.names[importPath] = name
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			local[name] = importPath
+			if imp.selected[importPath] == nil {
+				imp.selected[importPath] = make(map[string]bool)
+			}
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if x, ok := sel.X.(*ast.Ident); ok {
+				if importPath, ok := local[x.Name]; ok {
+					imp.selected[importPath][sel.Sel.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return imp
+}
+
+func (imp *opaqueImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := imp.packages[importPath]; ok {
+		return pkg, nil
+	}
+	name, ok := imp.names[importPath]
+	if !ok {
+		name = guessPackageName(importPath)
+	}
+	pkg := types.NewPackage(importPath, name)
+	for sel := range imp.selected[importPath] {
+		typeName := types.NewTypeName(token.NoPos, pkg, sel, nil)
+		types.NewNamed(typeName, types.NewStruct(nil, nil), nil)
+		pkg.Scope().Insert(typeName)
+	}
+	pkg.MarkComplete()
+	imp.packages[importPath] = pkg
+	return pkg, nil
+}
+
+var majorVersionSegment = regexp.MustCompile(`^v\d+$`)
+
+// guessPackageName guesses the package name from the import path with the
+// usual conventions, e.g. gopkg.in/yaml.v3 is yaml.
+func guessPackageName(importPath string) string {
+	segments := strings.Split(importPath, "/")
+	name := segments[len(segments)-1]
+	if majorVersionSegment.MatchString(name) && len(segments) > 1 {
+		name = segments[len(segments)-2]
+	}
+	if dot := strings.Index(name, ".v"); dot > 0 {
+		name = name[:dot]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	name = strings.TrimSuffix(name, ".go")
+	return strings.NewReplacer("-", "", ".", "_").Replace(name)
+}
+
+// apiReasons returns one reason per kind of breaking API change.
+func apiReasons(weights map[string]int, changes []APIChange) []Reason {
+	byKind := make(map[string][]APIChange)
+	for _, change := range changes {
+		byKind[change.Kind] = append(byKind[change.Kind], change)
+	}
+
+	reasons := make([]Reason, 0)
+	for _, kind := range []string{API_REMOVED, API_SIGNATURE, API_INTERFACE_METHOD, API_CHANGED} {
+		kindChanges := byKind[kind]
+		if len(kindChanges) == 0 || weights[kind] == 0 {
+			continue
+		}
+		match := kindChanges[0].String()
+		if len(kindChanges) > 1 {
+			match += fmt.Sprintf(" (+%d more)", len(kindChanges)-1)
+		}
+		reasons = append(reasons, Reason{Rule: "api " + strings.ReplaceAll(kind, "_", " "), Match: match, Contribution: weights[kind]})
+	}
+	return reasons
+}
@@ -0,0 +1,162 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffPackageAPI(t *testing.T) {
+	before := map[string]string{
+		"api/api.go": `package api
+
+import "net/http"
+
+type Handler interface {
+	Serve(r *http.Request) error
+}
+
+type Server struct {
+	Addr    string
+	Timeout int
+	secret  string
+}
+
+func (s *Server) Start(addr string) error { return nil }
+
+func New(addr string) *Server { return nil }
+
+func Helper() {}
+
+func internalHelper() {}
+
+var Default = New("")
+
+const Version = "1.0"
+`,
+		"api/api_linux.go": `//go:build linux
+
+package api
+
+func Platform() string { return "linux" }
+`,
+		"api/cmd.go": `package main
+
+func main() {}
+`,
+	}
+	after := map[string]string{
+		"api/api.go": `package api
+
+import (
+	"context"
+	nethttp "net/http"
+)
+
+type Handler interface {
+	Serve(r *nethttp.Response) error
+	Close() error
+}
+
+type Server struct {
+	Addr    string
+	Timeout int64
+	Extra   bool
+}
+
+func (s *Server) Start(ctx context.Context, addr string) error { return nil }
+
+func New(addr string) *Server { return nil }
+
+func internalHelper() {}
+
+func NewHelper() {}
+
+var Default = New("")
+
+const Version = "2.0"
+`,
+		"api/api_linux.go": `//go:build linux
+
+package api
+
+func Platform() string { return "linux" }
+`,
+	}
+
+	_, beforeAPI := packageAPI("api", before)
+	name, afterAPI := packageAPI("api", after)
+	if name != "api" {
+		t.Errorf("expected package name api, got %s", name)
+	}
+	changes := diffAPI("api", beforeAPI, afterAPI)
+	expected := []APIChange{
+		{Package: "api", Name: "Handler.Close", Kind: API_INTERFACE_METHOD, New: "func() error"},
+		{Package: "api", Name: "Handler.Serve", Kind: API_SIGNATURE, Old: "func(r *net/http.Request) error", New: "func(r *net/http.Response) error"},
+		{Package: "api", Name: "Helper", Kind: API_REMOVED, Old: "func()"},
+		{Package: "api", Name: "Server.Start", Kind: API_SIGNATURE, Old: "func(addr string) error", New: "func(ctx context.Context, addr string) error"},
+		{Package: "api", Name: "Server.Timeout", Kind: API_CHANGED, Old: "int", New: "int64"},
+	}
+	if diff := cmp.Diff(changes, expected); diff != "" {
+		t.Errorf("unexpected changes: %s", diff)
+	}
+}
+
+func TestPackageAPIWithoutPackage(t *testing.T) {
+	name, api := packageAPI("cmd", map[string]string{"cmd/main.go": "package main\n\nfunc main() {}\n"})
+	if name != "" || api != nil {
+		t.Errorf("expected no API for a main package, got %s %v", name, api)
+	}
+
+	changes := diffAPI("cmd", map[string]apiSymbol{"Run": {kind: "func", desc: "func()"}}, nil)
+	expected := []APIChange{{Package: "cmd", Name: "Run", Kind: API_REMOVED, Old: "func()"}}
+	if diff := cmp.Diff(changes, expected); diff != "" {
+		t.Errorf("unexpected changes: %s", diff)
+	}
+}
+
+func TestGoPackageDirs(t *testing.T) {
+	diff := &Diff{Stats: []NumStat{
+		{File: "main.go"},
+		{File: "pkg/a/a.go"},
+		{File: "pkg/a/a_test.go"},
+		{File: "pkg/{b => c}/b.go"},
+		{File: "internal/x/x.go"},
+		{File: "pkg/testdata/t.go"},
+		{File: "README.md"},
+	}}
+	expected := []string{".", "pkg/a", "pkg/b", "pkg/c"}
+	if diff := cmp.Diff(goPackageDirs(diff), expected); diff != "" {
+		t.Errorf("unexpected dirs: %s", diff)
+	}
+}
+
+func TestGuessPackageName(t *testing.T) {
+	tc := map[string]string{
+		"net/http":                    "http",
+		"gopkg.in/yaml.v3":            "yaml",
+		"github.com/foo/bar/v2":       "bar",
+		"github.com/mattn/go-sqlite3": "sqlite3",
+		"github.com/foo/client-go":    "client",
+	}
+	for importPath, expected := range tc {
+		if name := guessPackageName(importPath); name != expected {
+			t.Errorf("expected %s for %s, got %s", expected, importPath, name)
+		}
+	}
+}
+
+func TestAPIReasons(t *testing.T) {
+	changes := []APIChange{
+		{Package: "api", Name: "Server.Timeout", Kind: API_CHANGED, Old: "int", New: "int64"},
+		{Package: "api", Name: "Helper", Kind: API_REMOVED, Old: "func()"},
+		{Package: "api", Name: "Other", Kind: API_REMOVED, Old: "func()"},
+	}
+	expected := []Reason{
+		{Rule: "api removed", Match: "api.Helper removed (+1 more)", Contribution: 50},
+		{Rule: "api changed", Match: "api.Server.Timeout int → int64", Contribution: 40},
+	}
+	if diff := cmp.Diff(apiReasons(defaultAPIWeights, changes), expected); diff != "" {
+		t.Errorf("unexpected reasons: %s", diff)
+	}
+}
@@ -0,0 +1,319 @@
+package deckard
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type goModAnalyzer struct{}
+
+func (goModAnalyzer) handles(file string) bool {
+	return path.Base(file) == "go.mod"
+}
+
+func (goModAnalyzer) ecosystem() string {
+	return "Go"
+}
+
+func (goModAnalyzer) diff(manifest, before, after string) ([]DependencyChange, error) {
+	return diffGoMod(manifest, parseGoMod(before), parseGoMod(after)), nil
+}
+
+type goMod struct {
+	requires  map[string]string // module path -> version
+	replaces  map[string]string // replaced module (with optional version) -> replacement
+	retracts  []string
+	toolchain string
+}
+
+// parseGoMod parses the parts of a go.mod file that are relevant for the
+// dependency analysis. It is lenient, unknown directives and malformed
+// lines (common in test fixtures) are ignored.
+func parseGoMod(content string) *goMod {
+	mod := &goMod{requires: make(map[string]string), replaces: make(map[string]string)}
+
+	block := ""
+	for _, rawLine := range strings.Split(content, "\n") {
+		line := stripGoModComment(rawLine)
+		if line == "" {
+			continue
+		}
+
+		if block != "" {
+			if line == ")" {
+				block = ""
+				continue
+			}
+			mod.parseDirective(block, line)
+			continue
+		}
+
+		verb, rest := splitGoModVerb(line)
+		if rest == "(" {
+			block = verb
+			continue
+		}
+		mod.parseDirective(verb, rest)
+	}
+	return mod
+}
+
+// parseDirective adds the directive to the module, a malformed one is skipped.
+func (mod *goMod) parseDirective(verb, args string) {
+	fields := strings.Fields(args)
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, `"`)
+	}
+
+	switch verb {
+	case "require":
+		if len(fields) != 2 {
+			return
+		}
+		mod.requires[fields[0]] = fields[1]
+	case "replace":
+		arrow := -1
+		for i, field := range fields {
+			if field == "=>" {
+				arrow = i
+			}
+		}
+		if arrow < 1 || arrow == len(fields)-1 {
+			return
+		}
+		mod.replaces[strings.Join(fields[:arrow], " ")] = strings.Join(fields[arrow+1:], " ")
+	case "retract":
+		mod.retracts = append(mod.retracts, strings.Join(fields, " "))
+	case "toolchain":
+		if len(fields) != 1 {
+			return
+		}
+		mod.toolchain = fields[0]
+	}
+}
+
+func stripGoModComment(line string) string {
+	if i := strings.Index(line, "//"); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+func splitGoModVerb(line string) (string, string) {
+	i := strings.IndexAny(line, " \t(")
+	if i < 0 {
+		return line, ""
+	}
+	return line[:i], strings.TrimSpace(line[i:])
+}
+
+// diffGoMod compares the go.mod before and after a commit. before or after
+// may be nil if the file did not exist.
+func diffGoMod(manifest string, before, after *goMod) []DependencyChange {
+	if before == nil {
+		before = parseGoMod("")
+	}
+	if after == nil {
+		after = parseGoMod("")
+	}
+
+	changes := make([]DependencyChange, 0)
+	added := make([]string, 0)
+	removed := make(map[string]bool)
+	removedByBase := make(map[string][]string) // module path without major version -> removed modules
+
+	for module := range before.requires {
+		if _, ok := after.requires[module]; !ok {
+			removed[module] = true
+			base := stripMajorSuffix(module)
+			removedByBase[base] = append(removedByBase[base], module)
+		}
+	}
+	for _, modules := range removedByBase {
+		sort.Strings(modules)
+	}
+	for module, newVersion := range after.requires {
+		oldVersion, ok := before.requires[module]
+		if !ok {
+			added = append(added, module)
+			continue
+		}
+		if oldVersion == newVersion {
+			continue
+		}
+		changes = append(changes, DependencyChange{
+			Manifest:   manifest,
+			Module:     module,
+			OldVersion: oldVersion,
+			NewVersion: newVersion,
+			Kind:       versionChangeKind(oldVersion, newVersion),
+		})
+	}
+
+	// a major upgrade of a module changes the path (foo -> foo/v2, yaml.v2 ->
+	// yaml.v3), match them up
+	sort.Strings(added)
+	for _, module := range added {
+		newVersion := after.requires[module]
+		base := stripMajorSuffix(module)
+		if candidates := removedByBase[base]; len(candidates) > 0 {
+			oldModule := candidates[0]
+			removedByBase[base] = candidates[1:]
+			delete(removed, oldModule)
+			changes = append(changes, DependencyChange{
+				Manifest:   manifest,
+				Module:     module,
+				OldVersion: before.requires[oldModule],
+				NewVersion: newVersion,
+				Kind:       versionChangeKind(before.requires[oldModule], newVersion),
+			})
+			continue
+		}
+		changes = append(changes, DependencyChange{Manifest: manifest, Module: module, NewVersion: newVersion, Kind: DEP_ADDED})
+	}
+	for module := range removed {
+		changes = append(changes, DependencyChange{Manifest: manifest, Module: module, OldVersion: before.requires[module], Kind: DEP_REMOVED})
+	}
+
+	for replaced, replacement := range after.replaces {
+		if before.replaces[replaced] != replacement {
+			changes = append(changes, DependencyChange{
+				Manifest:   manifest,
+				Module:     replaced,
+				OldVersion: before.replaces[replaced],
+				NewVersion: replacement,
+				Kind:       DEP_REPLACE,
+			})
+		}
+	}
+
+	knownRetracts := make(map[string]bool)
+	for _, retract := range before.retracts {
+		knownRetracts[retract] = true
+	}
+	for _, retract := range after.retracts {
+		if !knownRetracts[retract] {
+			changes = append(changes, DependencyChange{Manifest: manifest, NewVersion: retract, Kind: DEP_RETRACT})
+		}
+	}
+
+	if after.toolchain != "" && after.toolchain != before.toolchain {
+		changes = append(changes, DependencyChange{
+			Manifest:   manifest,
+			Module:     "toolchain",
+			OldVersion: before.toolchain,
+			NewVersion: after.toolchain,
+			Kind:       DEP_TOOLCHAIN,
+		})
+	}
+
+	sortDependencyChanges(changes)
+	return changes
+}
+
+var majorSuffix = regexp.MustCompile(`(/v[0-9]+|\.v[0-9]+)$`)
+
+func stripMajorSuffix(module string) string {
+	return majorSuffix.ReplaceAllString(module, "")
+}
+
+func versionChangeKind(oldVersion, newVersion string) string {
+	oldVersion, newVersion = normalizeVersion(oldVersion), normalizeVersion(newVersion)
+	if len(parseSemver(oldVersion).parts) == 0 || len(parseSemver(newVersion).parts) == 0 {
+		return DEP_UPGRADED // not comparable (git refs, paths, ...)
+	}
+	cmp := compareSemver(oldVersion, newVersion)
+	if cmp > 0 {
+		return DEP_DOWNGRADED
+	}
+	if semverMajor(oldVersion) != semverMajor(newVersion) {
+		return DEP_MAJOR_UPGRADE
+	}
+	return DEP_UPGRADED
+}
+
+var pseudoVersion = regexp.MustCompile(`[-.][0-9]{14}-[0-9a-f]{12}(\+incompatible)?$`)
+
+// isPseudoVersion reports whether the version references an untagged commit.
+func isPseudoVersion(version string) bool {
+	return pseudoVersion.MatchString(version)
+}
+
+// normalizeVersion strips version range operators (^1.2, >=1.2, ~=1.2, ...)
+// and returns the first version of a version list or range.
+func normalizeVersion(version string) string {
+	version = strings.TrimLeft(version, "^~=<>!* ")
+	if i := strings.IndexAny(version, ", "); i > 0 {
+		version = version[:i]
+	}
+	return version
+}
+
+type semver struct {
+	parts []int
+	pre   string
+}
+
+func parseSemver(version string) semver {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i]
+	}
+	pre := ""
+	if i := strings.Index(version, "-"); i >= 0 {
+		version, pre = version[:i], version[i+1:]
+	}
+	var parts []int
+	for _, part := range strings.Split(version, ".") {
+		num, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		parts = append(parts, num)
+	}
+	return semver{parts: parts, pre: pre}
+}
+
+func semverMajor(version string) int {
+	parsed := parseSemver(version)
+	if len(parsed.parts) == 0 {
+		return 0
+	}
+	return parsed.parts[0]
+}
+
+// compareSemver returns -1, 0 or 1 if a is smaller, equal or bigger than b.
+func compareSemver(a, b string) int {
+	va, vb := parseSemver(a), parseSemver(b)
+	for i := 0; i < len(va.parts) || i < len(vb.parts); i++ {
+		pa, pb := 0, 0
+		if i < len(va.parts) {
+			pa = va.parts[i]
+		}
+		if i < len(vb.parts) {
+			pb = vb.parts[i]
+		}
+		if pa != pb {
+			if pa < pb {
+				return -1
+			}
+			return 1
+		}
+	}
+	// a pre-release has a lower precedence than the release
+	switch {
+	case va.pre == vb.pre:
+		return 0
+	case va.pre == "":
+		return 1
+	case vb.pre == "":
+		return -1
+	case va.pre < vb.pre:
+		return -1
+	default:
+		return 1
+	}
+}
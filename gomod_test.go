@@ -0,0 +1,167 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const goModBefore = `module example.com/app
+
+go 1.20
+
+require (
+	github.com/foo/bar v1.2.0
+	golang.org/x/net v0.1.0 // indirect
+	github.com/old/lib v1.0.0
+	github.com/down/grade v1.5.0
+)
+
+require github.com/pkg/errors v0.9.1
+`
+
+const goModAfter = `module example.com/app
+
+go 1.20
+
+toolchain go1.21.3
+
+require (
+	github.com/foo/bar/v2 v2.0.1
+	golang.org/x/net v0.7.0 // indirect
+	github.com/down/grade v1.4.0
+	github.com/new/dep v0.0.0-20220101120000-abcdef123456
+)
+
+require github.com/pkg/errors v0.9.1
+
+replace github.com/pkg/errors => ../errors
+
+retract [v1.0.0, v1.0.5] // broken
+`
+
+func TestDiffGoMod(t *testing.T) {
+	changes := diffGoMod("go.mod", parseGoMod(goModBefore), parseGoMod(goModAfter))
+	expected := []DependencyChange{
+		{Manifest: "go.mod", Module: "github.com/new/dep", NewVersion: "v0.0.0-20220101120000-abcdef123456", Kind: DEP_ADDED},
+		{Manifest: "go.mod", Module: "github.com/down/grade", OldVersion: "v1.5.0", NewVersion: "v1.4.0", Kind: DEP_DOWNGRADED},
+		{Manifest: "go.mod", Module: "github.com/foo/bar/v2", OldVersion: "v1.2.0", NewVersion: "v2.0.1", Kind: DEP_MAJOR_UPGRADE},
+		{Manifest: "go.mod", Module: "github.com/old/lib", OldVersion: "v1.0.0", Kind: DEP_REMOVED},
+		{Manifest: "go.mod", Module: "github.com/pkg/errors", NewVersion: "../errors", Kind: DEP_REPLACE},
+		{Manifest: "go.mod", NewVersion: "[v1.0.0, v1.0.5]", Kind: DEP_RETRACT},
+		{Manifest: "go.mod", Module: "toolchain", NewVersion: "go1.21.3", Kind: DEP_TOOLCHAIN},
+		{Manifest: "go.mod", Module: "golang.org/x/net", OldVersion: "v0.1.0", NewVersion: "v0.7.0", Kind: DEP_UPGRADED},
+	}
+	if diff := cmp.Diff(changes, expected); diff != "" {
+		t.Errorf("unexpected changes: %s", diff)
+	}
+}
+
+func TestDiffGoModNewFile(t *testing.T) {
+	after := parseGoMod("module example.com/app\n\nrequire github.com/foo/bar v1.0.0\n")
+	changes := diffGoMod("go.mod", nil, after)
+	expected := []DependencyChange{
+		{Manifest: "go.mod", Module: "github.com/foo/bar", NewVersion: "v1.0.0", Kind: DEP_ADDED},
+	}
+	if diff := cmp.Diff(changes, expected); diff != "" {
+		t.Errorf("unexpected changes: %s", diff)
+	}
+}
+
+func TestDiffGoModMajorVersions(t *testing.T) {
+	before := parseGoMod(`module example.com/app
+
+require (
+	github.com/foo/bar v1.5.0
+	github.com/foo/bar/v2 v2.3.0
+	github.com/baz/qux v1.0.0
+	github.com/baz/qux/v2 v2.0.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+`)
+	after := parseGoMod(`module example.com/app
+
+require (
+	github.com/baz/qux/v3 v3.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+`)
+	changes := diffGoMod("go.mod", before, after)
+	expected := []DependencyChange{
+		{Manifest: "go.mod", Module: "github.com/baz/qux/v3", OldVersion: "v1.0.0", NewVersion: "v3.0.0", Kind: DEP_MAJOR_UPGRADE},
+		{Manifest: "go.mod", Module: "gopkg.in/yaml.v3", OldVersion: "v2.4.0", NewVersion: "v3.0.1", Kind: DEP_MAJOR_UPGRADE},
+		{Manifest: "go.mod", Module: "github.com/baz/qux/v2", OldVersion: "v2.0.0", Kind: DEP_REMOVED},
+		{Manifest: "go.mod", Module: "github.com/foo/bar", OldVersion: "v1.5.0", Kind: DEP_REMOVED},
+		{Manifest: "go.mod", Module: "github.com/foo/bar/v2", OldVersion: "v2.3.0", Kind: DEP_REMOVED},
+	}
+	if diff := cmp.Diff(changes, expected); diff != "" {
+		t.Errorf("unexpected changes: %s", diff)
+	}
+}
+
+func TestDiffGoModMalformedLines(t *testing.T) {
+	after := parseGoMod(`module example.com/fixture
+
+require (
+	github.com/foo/bar v1.0.0
+	github.com/broken
+	github.com/too/many v1.0.0 v1.1.0
+)
+
+replace => ../nowhere
+
+toolchain go1.21 go1.22
+`)
+	changes := diffGoMod("testdata/go.mod", nil, after)
+	expected := []DependencyChange{
+		{Manifest: "testdata/go.mod", Module: "github.com/foo/bar", NewVersion: "v1.0.0", Kind: DEP_ADDED},
+	}
+	if diff := cmp.Diff(changes, expected); diff != "" {
+		t.Errorf("unexpected changes: %s", diff)
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	tc := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"v1.0.0", "v1.0.0", 0},
+		{"v1.0.0", "v1.0.1", -1},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v2.0.0+incompatible", "v1.9.0", 1},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+		{"v0.0.0-20220101120000-abcdef123456", "v0.0.0-20230101120000-abcdef123456", -1},
+	}
+
+	for _, c := range tc {
+		t.Run(c.a+" "+c.b, func(t *testing.T) {
+			if result := compareSemver(c.a, c.b); result != c.expected {
+				t.Errorf("expected %d, got %d", c.expected, result)
+			}
+		})
+	}
+}
+
+func TestIsPseudoVersion(t *testing.T) {
+	tc := []struct {
+		version  string
+		expected bool
+	}{
+		{"v1.2.3", false},
+		{"v1.2.3-rc.1", false},
+		{"v0.0.0-20220101120000-abcdef123456", true},
+		{"v1.2.4-0.20220101120000-abcdef123456", true},
+		{"v1.2.3-pre.0.20220101120000-abcdef123456", true},
+		{"v2.0.0-20220101120000-abcdef123456+incompatible", true},
+	}
+
+	for _, c := range tc {
+		t.Run(c.version, func(t *testing.T) {
+			if result := isPseudoVersion(c.version); result != c.expected {
+				t.Errorf("expected %t, got %t", c.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package deckard
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	VERDICT_INTERESTING = "interesting"
+	VERDICT_FALSE_ALARM = "false_alarm"
+)
+
+// WeightFit is the refitted weight factor of a rule in a project. The factor
+// is applied to the contribution of every reason of the rule.
+type WeightFit struct {
+	Rule        string
+	OldFactor   float64
+	NewFactor   float64
+	Interesting int // labelled commits the rule fired on
+	FalseAlarms int
+}
+
+// labelledCommit is a commit with a review verdict and the rules that fired on it.
+type labelledCommit struct {
+	verdict string
+	rules   []string
+}
+
+// fitWeights computes a factor per rule from the verdicts. A rule that fires
+// on interesting commits more often than the project average is emphasized,
+// one that mostly fires on false alarms is discounted. Rules with less than
+// MinLabels labelled commits keep their factor.
+func fitWeights(conf ConfigLearning, labels []labelledCommit, current map[string]float64) []WeightFit {
+	interesting := 0
+	byRule := make(map[string]*WeightFit)
+	for _, label := range labels {
+		if label.verdict == VERDICT_INTERESTING {
+			interesting++
+		}
+		seen := make(map[string]bool)
+		for _, rule := range label.rules {
+			if seen[rule] {
+				continue
+			}
+			seen[rule] = true
+			fit, ok := byRule[rule]
+			if !ok {
+				fit = &WeightFit{Rule: rule, OldFactor: 1}
+				if factor, ok := current[rule]; ok {
+					fit.OldFactor = factor
+				}
+				byRule[rule] = fit
+			}
+			if label.verdict == VERDICT_INTERESTING {
+				fit.Interesting++
+			} else {
+				fit.FalseAlarms++
+			}
+		}
+	}
+
+	// the factor is the (Laplace smoothed) precision of the rule relative to
+	// the one of all labelled commits
+	baseline := float64(interesting+1) / float64(len(labels)+2)
+	fits := make([]WeightFit, 0)
+	for _, fit := range byRule {
+		labelled := fit.Interesting + fit.FalseAlarms
+		if labelled < conf.MinLabels {
+			continue
+		}
+		precision := float64(fit.Interesting+1) / float64(labelled+2)
+		factor := math.Max(conf.MinFactor, math.Min(conf.MaxFactor, precision/baseline))
+		fit.NewFactor = math.Round(factor*100) / 100
+		fits = append(fits, *fit)
+	}
+	sort.Slice(fits, func(i, j int) bool {
+		return fits[i].Rule < fits[j].Rule
+	})
+	return fits
+}
+
+// applyWeightFactors scales the contributions of the reasons with the fitted
+// factors of the project. Leaked secrets are never scaled down.
+func applyWeightFactors(factors map[string]float64, reasons []Reason) {
+	for i, reason := range reasons {
+		if isSecretReason(reason) {
+			continue
+		}
+		if factor, ok := factors[reason.Rule]; ok {
+			reasons[i].Contribution = int(math.Round(float64(reason.Contribution) * factor))
+			if reasons[i].Contribution > maxSlatScore {
+				reasons[i].Contribution = maxSlatScore
+			}
+		}
+	}
+}
+
+// RefitWeights fits the weight factors of the project, or of all projects if
+// project is empty, from the review verdicts and stores them. Unless force is
+// set, only projects without a fit in the last RefitDays are fitted. The
+// returned fits are keyed by project.
+func RefitWeights(config *Config, db *sql.DB, project string, force bool) (map[string][]WeightFit, error) {
+	projects := make([]string, 0, len(config.Projects))
+	if project != "" {
+		if _, ok := config.Projects[project]; !ok {
+			return nil, fmt.Errorf("unknown project '%s'", project)
+		}
+		projects = append(projects, project)
+	} else {
+		for prj := range config.Projects {
+			projects = append(projects, prj)
+		}
+		sort.Strings(projects)
+	}
+
+	now := time.Now()
+	result := make(map[string][]WeightFit)
+	for _, prj := range projects {
+		if !force {
+			if config.Learning.RefitDays == 0 {
+				continue
+			}
+			last, err := loadLastWeightFit(db, prj)
+			if err != nil {
+				return nil, err
+			}
+			if last != nil && now.Sub(*last) < time.Duration(config.Learning.RefitDays)*24*time.Hour {
+				continue
+			}
+		}
+
+		labels, err := loadLabelledCommits(db, prj)
+		if err != nil {
+			return nil, err
+		}
+		current, err := loadWeightFactors(db, prj)
+		if err != nil {
+			return nil, err
+		}
+		fits := fitWeights(config.Learning, labels, current)
+		err = storeWeightFits(db, prj, fits, now)
+		if err != nil {
+			return nil, err
+		}
+		result[prj] = fits
+	}
+	return result, nil
+}
+
+// weightReport lists the fitted factors, e.g. "keyword revert: 1.00 → 0.40 (1 interesting, 7 false alarms)".
+func weightReport(fits []WeightFit) string {
+	if len(fits) == 0 {
+		return "no rule with enough labelled commits\n"
+	}
+	var sb strings.Builder
+	for _, fit := range fits {
+		fmt.Fprintf(&sb, "%s: %.2f → %.2f (%d interesting, %d false alarms)\n", fit.Rule, fit.OldFactor, fit.NewFactor, fit.Interesting, fit.FalseAlarms)
+	}
+	return sb.String()
+}
+
+// WeightReport returns the report of the last fit per project, or of the
+// project if not empty.
+func WeightReport(config *Config, db *sql.DB, project string) (string, error) {
+	projects := make([]string, 0, len(config.Projects))
+	for prj := range config.Projects {
+		if project == "" || prj == project {
+			projects = append(projects, prj)
+		}
+	}
+	if len(projects) == 0 {
+		return "", fmt.Errorf("unknown project '%s'", project)
+	}
+	sort.Strings(projects)
+
+	var sb strings.Builder
+	for _, prj := range projects {
+		last, err := loadLastWeightFit(db, prj)
+		if err != nil {
+			return "", err
+		}
+		if last == nil {
+			fmt.Fprintf(&sb, "[%s] never fitted\n", prj)
+			continue
+		}
+		fits, err := loadWeightFits(db, prj, *last)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "[%s] fitted %s\n", prj, last.Format("2006-01-02 15:04"))
+		sb.WriteString(weightReport(fits))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,69 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFitWeights(t *testing.T) {
+	conf := ConfigLearning{MinLabels: 3, MinFactor: 0.25, MaxFactor: 2}
+	labels := []labelledCommit{
+		{verdict: VERDICT_INTERESTING, rules: []string{"build ci", "keyword security"}},
+		{verdict: VERDICT_INTERESTING, rules: []string{"build ci"}},
+		{verdict: VERDICT_INTERESTING, rules: []string{"build ci", "build ci"}},
+		{verdict: VERDICT_FALSE_ALARM, rules: []string{"keyword revert"}},
+		{verdict: VERDICT_FALSE_ALARM, rules: []string{"keyword revert", "keyword security"}},
+		{verdict: VERDICT_FALSE_ALARM, rules: []string{"keyword revert"}},
+		{verdict: VERDICT_FALSE_ALARM, rules: []string{"keyword revert"}},
+		{verdict: VERDICT_FALSE_ALARM},
+	}
+	current := map[string]float64{"keyword revert": 0.5}
+
+	// baseline precision is (3+1)/(8+2) = 0.4
+	expected := []WeightFit{
+		{Rule: "build ci", OldFactor: 1, NewFactor: 2, Interesting: 3},            // 0.8 / 0.4
+		{Rule: "keyword revert", OldFactor: 0.5, NewFactor: 0.42, FalseAlarms: 4}, // 0.1667 / 0.4
+	}
+	fits := fitWeights(conf, labels, current)
+	if diff := cmp.Diff(fits, expected); diff != "" {
+		t.Errorf("unexpected fits: %s", diff)
+	}
+}
+
+func TestApplyWeightFactors(t *testing.T) {
+	reasons := []Reason{
+		{Rule: "build ci", Contribution: 40},
+		{Rule: "keyword revert", Contribution: 10},
+		{Rule: "secret aws key", Contribution: 100},
+	}
+	factors := map[string]float64{"build ci": 1.5, "keyword revert": 0.25, "secret aws key": 0.25}
+	applyWeightFactors(factors, reasons)
+	expected := []Reason{
+		{Rule: "build ci", Contribution: 60},
+		{Rule: "keyword revert", Contribution: 3},
+		{Rule: "secret aws key", Contribution: 100},
+	}
+	if diff := cmp.Diff(reasons, expected); diff != "" {
+		t.Errorf("unexpected reasons: %s", diff)
+	}
+}
+
+func TestSlatScoreWithFactors(t *testing.T) {
+	rules := []ConfigRule{{Name: "modules", Weight: 60, Paths: []string{"go.mod"}}}
+	scorer, err := newSlatScorer(&Config{Rules: rules})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	history := &projectHistory{factors: map[string]float64{"modules": 0.5}}
+	score, reasons, err := scorer.slatScore(history, &Commit{Subject: "bump"}, &Diff{Stats: []NumStat{{File: "go.mod", Added: 1}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if score != 30 {
+		t.Errorf("expected score 30, got %d", score)
+	}
+	if diff := cmp.Diff(reasons, []Reason{{Rule: "modules", Match: "go.mod", Contribution: 30}}); diff != "" {
+		t.Errorf("unexpected reasons: %s", diff)
+	}
+}
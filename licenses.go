@@ -0,0 +1,387 @@
+package deckard
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	LICENSE_FILE       = "file"       // LICENSE, COPYING or NOTICE file changed
+	LICENSE_HEADER     = "header"     // SPDX header changed or differing from the project license
+	LICENSE_DEPENDENCY = "dependency" // added dependency with another license than the project
+	LICENSE_RELICENSED = "relicensed" // upgraded dependency changed its license
+)
+
+// weights used if not overwritten in the config
+var defaultLicenseWeights = map[string]int{
+	LICENSE_FILE:       50,
+	LICENSE_HEADER:     30,
+	LICENSE_DEPENDENCY: 40,
+	LICENSE_RELICENSED: 60,
+}
+
+var licenseRuleNames = map[string]string{
+	LICENSE_FILE:       "license file changed",
+	LICENSE_HEADER:     "license header changed",
+	LICENSE_DEPENDENCY: "dependency license",
+	LICENSE_RELICENSED: "dependency relicensed",
+}
+
+// read from a license file to identify the license
+const licenseHeadSize = 16 * 1024
+
+// LICENSE, COPYING.LESSER, LICENSE-APACHE, licence.txt, ... but not license_check.go
+var licenseFileName = regexp.MustCompile(`^(?i:licen[cs]e|copying|notice|unlicense)([.\-_][A-Z0-9][A-Za-z0-9.\-]*|\.(?i:md|txt|rst|markdown))?$`)
+
+var spdxIdentifier = regexp.MustCompile(`SPDX-License-Identifier:\s*([A-Za-z0-9.\-+]+(\s+(AND|OR|WITH)\s+[A-Za-z0-9.\-+]+)*)`)
+
+// LicenseChange is a change of a commit that affects the licensing of the project.
+type LicenseChange struct {
+	Kind   string // one of the LICENSE_ constants
+	Item   string // file, file:line or module
+	Detail string
+}
+
+func (c LicenseChange) String() string {
+	if c.Detail == "" {
+		return c.Item
+	}
+	return c.Item + " " + c.Detail
+}
+
+func isLicenseFile(file string) bool {
+	return licenseFileName.MatchString(path.Base(file))
+}
+
+// licenseFingerprints identify the common licenses by phrases of their text,
+// more specific licenses come first.
+var licenseFingerprints = []struct {
+	id      string
+	phrases []string
+}{
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"EPL-2.0", []string{"eclipse public license", "2.0"}},
+	{"BSL-1.0", []string{"boost software license"}},
+	{"BUSL-1.1", []string{"business source license"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"MIT", []string{"permission is hereby granted, free of charge"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+}
+
+// identifyLicense returns the SPDX identifier of the license text, empty if
+// the license is unknown.
+func identifyLicense(text string) string {
+	if match := spdxIdentifier.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+	normalized := strings.Join(strings.FieldsFunc(strings.ToLower(text), unicode.IsSpace), " ")
+	for _, fingerprint := range licenseFingerprints {
+		matches := true
+		for _, phrase := range fingerprint.phrases {
+			if !strings.Contains(normalized, phrase) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return fingerprint.id
+		}
+	}
+	return ""
+}
+
+// sameLicense compares SPDX identifiers, ignoring the -only and -or-later variants.
+func sameLicense(a, b string) bool {
+	normalize := func(id string) string {
+		id = strings.ToLower(id)
+		id = strings.TrimSuffix(id, "+")
+		id = strings.TrimSuffix(id, "-only")
+		return strings.TrimSuffix(id, "-or-later")
+	}
+	return normalize(a) == normalize(b)
+}
+
+func licenseAllowed(allowed []string, license string) bool {
+	for _, id := range allowed {
+		if sameLicense(id, license) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectLicense identifies the license of the project from its license file
+// at rev, empty if unknown.
+func projectLicense(targetFolder, rev string) (string, error) {
+	files, err := listFiles(targetFolder, rev, ".")
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if !isLicenseFile(file) || strings.HasPrefix(strings.ToLower(file), "notice") {
+			continue
+		}
+		head, _, err := headFile(targetFolder, rev, file, licenseHeadSize)
+		if err != nil {
+			return "", err
+		}
+		if license := identifyLicense(string(head)); license != "" {
+			return license, nil
+		}
+	}
+	return "", nil
+}
+
+// licenseChanges collects the license relevant changes of the commit.
+// license is the declared license of the project, empty to identify it from
+// the license file.
+func licenseChanges(conf ConfigLicenses, license, targetFolder, hash string, diff *Diff) ([]LicenseChange, error) {
+	if license == "" && needsProjectLicense(diff) {
+		var err error
+		license, err = projectLicense(targetFolder, hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	changes := make([]LicenseChange, 0)
+	for _, stat := range diff.Stats {
+		file := newFileName(stat.File)
+		if !isLicenseFile(file) {
+			continue
+		}
+		oldHead, oldFound, err := headFile(targetFolder, hash+"^", oldFileName(stat.File), licenseHeadSize)
+		if err != nil {
+			return nil, err
+		}
+		newHead, newFound, err := headFile(targetFolder, hash, file, licenseHeadSize)
+		if err != nil {
+			return nil, err
+		}
+		oldLicense, newLicense := identifyLicense(string(oldHead)), identifyLicense(string(newHead))
+		detail := ""
+		switch {
+		case !oldFound:
+			detail = fmt.Sprintf("(added %s)", licenseName(newLicense))
+		case !newFound:
+			detail = fmt.Sprintf("(deleted %s)", licenseName(oldLicense))
+		case oldLicense != newLicense:
+			detail = fmt.Sprintf("(%s → %s)", licenseName(oldLicense), licenseName(newLicense))
+		}
+		changes = append(changes, LicenseChange{Kind: LICENSE_FILE, Item: file, Detail: detail})
+	}
+
+	changes = append(changes, headerChanges(conf, license, diff)...)
+
+	depChanges, err := dependencyLicenseChanges(conf, license, targetFolder, hash, diff.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, depChanges...), nil
+}
+
+// needsProjectLicense reports whether the diff has SPDX headers or added Go
+// modules to compare with the project license.
+func needsProjectLicense(diff *Diff) bool {
+	for _, dep := range diff.Dependencies {
+		if dep.Kind == DEP_ADDED && path.Base(dep.Manifest) == "go.mod" {
+			return true
+		}
+	}
+	for _, patch := range diff.Patch {
+		for _, line := range patch.Added {
+			if strings.Contains(line.Text, "SPDX-License-Identifier:") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func licenseName(license string) string {
+	if license == "" {
+		return "unknown license"
+	}
+	return license
+}
+
+// headerChanges finds added SPDX headers in handwritten files that replace
+// an existing header or that differ from the project license.
+func headerChanges(conf ConfigLicenses, license string, diff *Diff) []LicenseChange {
+	changes := make([]LicenseChange, 0)
+	for _, patch := range diff.Patch {
+		if diff.fileClass(patch.File) != FILE_HANDWRITTEN {
+			continue
+		}
+		replaced := false
+		for _, line := range patch.Removed {
+			if strings.Contains(line.Text, "SPDX-License-Identifier:") {
+				replaced = true
+			}
+		}
+		for _, line := range patch.Added {
+			match := spdxIdentifier.FindStringSubmatch(line.Text)
+			if match == nil {
+				continue
+			}
+			differs := license != "" && !sameLicense(match[1], license) && !licenseAllowed(conf.Allowed, match[1])
+			if differs || replaced {
+				changes = append(changes, LicenseChange{Kind: LICENSE_HEADER, Item: fmt.Sprintf("%s:%d", patch.File, line.Num), Detail: match[1]})
+			}
+		}
+	}
+	return changes
+}
+
+// dependencyLicenseChanges compares the licenses of added and upgraded Go
+// modules with the project license and their old version. The licenses are
+// read from the vendor folder of the commit or from the module cache.
+func dependencyLicenseChanges(conf ConfigLicenses, license, targetFolder, hash string, deps []DependencyChange) ([]LicenseChange, error) {
+	changes := make([]LicenseChange, 0)
+	for _, dep := range deps {
+		if path.Base(dep.Manifest) != "go.mod" || dep.Module == "" {
+			continue
+		}
+		switch dep.Kind {
+		case DEP_ADDED:
+			depLicense, err := moduleLicense(conf, targetFolder, hash, dep.Manifest, dep.Module, dep.NewVersion)
+			if err != nil {
+				return nil, err
+			}
+			if depLicense == "" || license == "" || sameLicense(depLicense, license) || licenseAllowed(conf.Allowed, depLicense) {
+				continue
+			}
+			changes = append(changes, LicenseChange{Kind: LICENSE_DEPENDENCY, Item: dep.Module, Detail: fmt.Sprintf("%s %s (project %s)", dep.NewVersion, depLicense, license)})
+		case DEP_UPGRADED, DEP_MAJOR_UPGRADE, DEP_DOWNGRADED:
+			oldLicense, err := moduleLicense(conf, targetFolder, hash+"^", dep.Manifest, dep.Module, dep.OldVersion)
+			if err != nil {
+				return nil, err
+			}
+			newLicense, err := moduleLicense(conf, targetFolder, hash, dep.Manifest, dep.Module, dep.NewVersion)
+			if err != nil {
+				return nil, err
+			}
+			if oldLicense == "" || newLicense == "" || sameLicense(oldLicense, newLicense) {
+				continue
+			}
+			changes = append(changes, LicenseChange{Kind: LICENSE_RELICENSED, Item: dep.Module, Detail: fmt.Sprintf("%s → %s %s → %s", dep.OldVersion, dep.NewVersion, oldLicense, newLicense)})
+		}
+	}
+	return changes, nil
+}
+
+// moduleLicense identifies the license of the module version, empty if no
+// license file is found.
+func moduleLicense(conf ConfigLicenses, targetFolder, rev, manifest, module, version string) (string, error) {
+	vendorDir := path.Join(path.Dir(manifest), "vendor", module)
+	files, err := listFiles(targetFolder, rev, vendorDir)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if path.Dir(file) != vendorDir || !isLicenseFile(file) {
+			continue
+		}
+		head, _, err := headFile(targetFolder, rev, file, licenseHeadSize)
+		if err != nil {
+			return "", err
+		}
+		if license := identifyLicense(string(head)); license != "" {
+			return license, nil
+		}
+	}
+
+	modCache := conf.ModCache
+	if modCache == "" {
+		modCache = defaultModCache()
+	}
+	if modCache == "" || version == "" {
+		return "", nil
+	}
+	dir := filepath.Join(modCache, escapeModulePath(module)+"@"+escapeModulePath(version))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil // not downloaded
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isLicenseFile(entry.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return "", err
+		}
+		if len(content) > licenseHeadSize {
+			content = content[:licenseHeadSize]
+		}
+		if license := identifyLicense(string(content)); license != "" {
+			return license, nil
+		}
+	}
+	return "", nil
+}
+
+// defaultModCache returns the module cache of the go command.
+func defaultModCache() string {
+	if modCache := os.Getenv("GOMODCACHE"); modCache != "" {
+		return modCache
+	}
+	if gopath := filepath.SplitList(os.Getenv("GOPATH")); len(gopath) > 0 && gopath[0] != "" {
+		return filepath.Join(gopath[0], "pkg", "mod")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "go", "pkg", "mod")
+}
+
+// escapeModulePath escapes upper case letters like the module cache does,
+// e.g. github.com/BurntSushi -> github.com/!burnt!sushi.
+func escapeModulePath(module string) string {
+	var sb strings.Builder
+	for _, r := range module {
+		if unicode.IsUpper(r) {
+			sb.WriteRune('!')
+			sb.WriteRune(unicode.ToLower(r))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// licenseReasons returns one reason per kind of license change.
+func licenseReasons(weights map[string]int, changes []LicenseChange) []Reason {
+	byKind := make(map[string][]LicenseChange)
+	for _, change := range changes {
+		byKind[change.Kind] = append(byKind[change.Kind], change)
+	}
+
+	reasons := make([]Reason, 0)
+	for _, kind := range []string{LICENSE_RELICENSED, LICENSE_FILE, LICENSE_DEPENDENCY, LICENSE_HEADER} {
+		kindChanges := byKind[kind]
+		if len(kindChanges) == 0 || weights[kind] == 0 {
+			continue
+		}
+		match := kindChanges[0].String()
+		if len(kindChanges) > 1 {
+			match += fmt.Sprintf(" (+%d more)", len(kindChanges)-1)
+		}
+		reasons = append(reasons, Reason{Rule: licenseRuleNames[kind], Match: match, Contribution: weights[kind]})
+	}
+	return reasons
+}
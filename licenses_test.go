@@ -0,0 +1,143 @@
+package deckard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIdentifyLicense(t *testing.T) {
+	tc := []struct {
+		text     string
+		expected string
+	}{
+		{"// SPDX-License-Identifier: GPL-2.0-only OR MIT\n", "GPL-2.0-only OR MIT"},
+		{"MIT License\n\nPermission is hereby granted, free of charge, to any person obtaining a copy", "MIT"},
+		{"                                 Apache License\n                           Version 2.0, January 2004", "Apache-2.0"},
+		{"GNU LESSER GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007", "LGPL-3.0"},
+		{"GNU GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007", "GPL-3.0"},
+		{"Redistribution and use in source and binary forms, with or without\nmodification ... Neither the name of", "BSD-3-Clause"},
+		{"Redistribution and use in source and binary forms, with or without", "BSD-2-Clause"},
+		{"Business Source License 1.1", "BUSL-1.1"},
+		{"All rights reserved.", ""},
+	}
+	for _, c := range tc {
+		if license := identifyLicense(c.text); license != c.expected {
+			t.Errorf("expected '%s' for %q, got '%s'", c.expected, c.text, license)
+		}
+	}
+}
+
+func TestIsLicenseFile(t *testing.T) {
+	tc := map[string]bool{
+		"LICENSE":              true,
+		"LICENSE.md":           true,
+		"sub/LICENSE-APACHE":   true,
+		"COPYING.LESSER":       true,
+		"NOTICE":               true,
+		"licence.txt":          true,
+		"LICENSE-MIT.txt":      true,
+		"license_check.go":     false,
+		"LICENSES/MIT.txt":     false,
+		"pkg/licensing/doc.go": false,
+	}
+	for file, expected := range tc {
+		if isLicenseFile(file) != expected {
+			t.Errorf("expected %v for %s", expected, file)
+		}
+	}
+}
+
+func TestSameLicense(t *testing.T) {
+	if !sameLicense("GPL-3.0-only", "gpl-3.0") || !sameLicense("GPL-2.0+", "GPL-2.0-or-later") {
+		t.Errorf("expected same licenses")
+	}
+	if sameLicense("GPL-2.0", "GPL-3.0") || sameLicense("MIT", "Apache-2.0") {
+		t.Errorf("expected different licenses")
+	}
+}
+
+func TestHeaderChanges(t *testing.T) {
+	diff := &Diff{
+		Stats: []NumStat{
+			{File: "a.go", Added: 1, Deleted: 1},
+			{File: "b.go", Added: 1, Deleted: 1},
+			{File: "new.go", Added: 10},
+			{File: "same.go", Added: 10},
+			{File: "vendor/x/y.go", Added: 10},
+		},
+		Patch: []FilePatch{
+			{File: "a.go", Added: []PatchLine{{Num: 1, Text: "// SPDX-License-Identifier: MIT"}}, Removed: []PatchLine{{Num: 1, Text: "// SPDX-License-Identifier: Apache-2.0"}}},
+			{File: "b.go", Added: []PatchLine{{Num: 1, Text: "// SPDX-License-Identifier: MIT"}}, Removed: []PatchLine{{Num: 7, Text: "\tfoo()"}}},
+			{File: "new.go", Added: []PatchLine{{Num: 1, Text: "// SPDX-License-Identifier: GPL-3.0-or-later"}}},
+			{File: "same.go", Added: []PatchLine{{Num: 1, Text: "// SPDX-License-Identifier: MIT"}}},
+			{File: "vendor/x/y.go", Added: []PatchLine{{Num: 1, Text: "// SPDX-License-Identifier: GPL-3.0"}}},
+		},
+		Classes: map[string]string{"vendor/x/y.go": FILE_VENDORED},
+	}
+	expected := []LicenseChange{
+		{Kind: LICENSE_HEADER, Item: "a.go:1", Detail: "MIT"},
+		{Kind: LICENSE_HEADER, Item: "new.go:1", Detail: "GPL-3.0-or-later"},
+	}
+	if diff := cmp.Diff(headerChanges(ConfigLicenses{}, "MIT", diff), expected); diff != "" {
+		t.Errorf("unexpected changes: %s", diff)
+	}
+	if changes := headerChanges(ConfigLicenses{Allowed: []string{"GPL-3.0"}}, "MIT", diff); len(changes) != 1 {
+		t.Errorf("expected only the replaced header, got %v", changes)
+	}
+}
+
+func TestDependencyLicenseChanges(t *testing.T) {
+	modCache := t.TempDir()
+	writeLicense := func(dir, text string) {
+		err := os.MkdirAll(filepath.Join(modCache, dir), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(modCache, dir, "LICENSE"), []byte(text), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeLicense("github.com/!burnt!sushi/toml@v1.0.0", "MIT License\nPermission is hereby granted, free of charge")
+	writeLicense("github.com/a/gpl@v1.0.0", "GNU GENERAL PUBLIC LICENSE\nVersion 3")
+	writeLicense("github.com/a/relicensed@v1.0.0", "Apache License\nVersion 2.0")
+	writeLicense("github.com/a/relicensed@v1.1.0", "Business Source License 1.1")
+
+	deps := []DependencyChange{
+		{Manifest: "go.mod", Module: "github.com/BurntSushi/toml", NewVersion: "v1.0.0", Kind: DEP_ADDED},
+		{Manifest: "go.mod", Module: "github.com/a/gpl", NewVersion: "v1.0.0", Kind: DEP_ADDED},
+		{Manifest: "go.mod", Module: "github.com/a/unknown", NewVersion: "v1.0.0", Kind: DEP_ADDED},
+		{Manifest: "go.mod", Module: "github.com/a/relicensed", OldVersion: "v1.0.0", NewVersion: "v1.1.0", Kind: DEP_UPGRADED},
+		{Manifest: "package.json", Module: "left-pad", NewVersion: "1.0.0", Kind: DEP_ADDED},
+	}
+	// not a git repository, so there are no vendored copies
+	changes, err := dependencyLicenseChanges(ConfigLicenses{ModCache: modCache}, "MIT", t.TempDir(), "HEAD", deps)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	expected := []LicenseChange{
+		{Kind: LICENSE_DEPENDENCY, Item: "github.com/a/gpl", Detail: "v1.0.0 GPL-3.0 (project MIT)"},
+		{Kind: LICENSE_RELICENSED, Item: "github.com/a/relicensed", Detail: "v1.0.0 → v1.1.0 Apache-2.0 → BUSL-1.1"},
+	}
+	if diff := cmp.Diff(changes, expected); diff != "" {
+		t.Errorf("unexpected changes: %s", diff)
+	}
+}
+
+func TestLicenseReasons(t *testing.T) {
+	changes := []LicenseChange{
+		{Kind: LICENSE_HEADER, Item: "a.go:1", Detail: "MIT"},
+		{Kind: LICENSE_FILE, Item: "LICENSE", Detail: "(MIT → BUSL-1.1)"},
+		{Kind: LICENSE_HEADER, Item: "b.go:1", Detail: "MIT"},
+	}
+	expected := []Reason{
+		{Rule: "license file changed", Match: "LICENSE (MIT → BUSL-1.1)", Contribution: 50},
+		{Rule: "license header changed", Match: "a.go:1 MIT (+1 more)", Contribution: 30},
+	}
+	if diff := cmp.Diff(licenseReasons(defaultLicenseWeights, changes), expected); diff != "" {
+		t.Errorf("unexpected reasons: %s", diff)
+	}
+}
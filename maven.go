@@ -0,0 +1,118 @@
+package deckard
+
+import (
+	"encoding/xml"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// mavenAnalyzer handles the dependencies and plugins of a pom.xml.
+type mavenAnalyzer struct{}
+
+func (mavenAnalyzer) handles(file string) bool {
+	return path.Base(file) == "pom.xml"
+}
+
+func (mavenAnalyzer) ecosystem() string {
+	return "Maven"
+}
+
+func (mavenAnalyzer) diff(manifest, before, after string) ([]DependencyChange, error) {
+	versionsBefore, err := parsePom(before)
+	if err != nil {
+		return nil, err
+	}
+	versionsAfter, err := parsePom(after)
+	if err != nil {
+		return nil, err
+	}
+	return diffVersions(manifest, versionsBefore, versionsAfter), nil
+}
+
+type pomArtifact struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type pomProperty struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type pom struct {
+	Version    string      `xml:"version"`
+	Parent     pomArtifact `xml:"parent"`
+	Properties struct {
+		Entries []pomProperty `xml:",any"`
+	} `xml:"properties"`
+
+	Dependencies               []pomArtifact `xml:"dependencies>dependency"`
+	ManagedDependencies        []pomArtifact `xml:"dependencyManagement>dependencies>dependency"`
+	Plugins                    []pomArtifact `xml:"build>plugins>plugin"`
+	ManagedPlugins             []pomArtifact `xml:"build>pluginManagement>plugins>plugin"`
+	ProfileDependencies        []pomArtifact `xml:"profiles>profile>dependencies>dependency"`
+	ProfileManagedDependencies []pomArtifact `xml:"profiles>profile>dependencyManagement>dependencies>dependency"`
+	ProfilePlugins             []pomArtifact `xml:"profiles>profile>build>plugins>plugin"`
+}
+
+var pomPropertyRef = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+func parsePom(content string) (map[string]string, error) {
+	versions := make(map[string]string)
+	if strings.TrimSpace(content) == "" {
+		return versions, nil
+	}
+
+	var doc pom
+	err := xml.Unmarshal([]byte(content), &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := map[string]string{
+		"project.version": doc.Version,
+		"version":         doc.Version,
+	}
+	if doc.Version == "" {
+		properties["project.version"] = doc.Parent.Version
+	}
+	properties["project.parent.version"] = doc.Parent.Version
+	for _, property := range doc.Properties.Entries {
+		properties[property.XMLName.Local] = strings.TrimSpace(property.Value)
+	}
+	resolve := func(value string) string {
+		return pomPropertyRef.ReplaceAllStringFunc(strings.TrimSpace(value), func(ref string) string {
+			if resolved, ok := properties[ref[2:len(ref)-1]]; ok {
+				return resolved
+			}
+			return ref
+		})
+	}
+
+	if doc.Parent.ArtifactID != "" {
+		versions[resolve(doc.Parent.GroupID)+":"+resolve(doc.Parent.ArtifactID)] = resolve(doc.Parent.Version)
+	}
+	lists := [][]pomArtifact{
+		doc.Dependencies, doc.ManagedDependencies, doc.Plugins, doc.ManagedPlugins,
+		doc.ProfileDependencies, doc.ProfileManagedDependencies, doc.ProfilePlugins,
+	}
+	for _, list := range lists {
+		for _, artifact := range list {
+			group := resolve(artifact.GroupID)
+			if group == "" {
+				group = "org.apache.maven.plugins" // default group of plugins
+			}
+			name, version := group+":"+resolve(artifact.ArtifactID), resolve(artifact.Version)
+			existing, ok := versions[name]
+			switch {
+			case !ok || existing == "":
+				versions[name] = version
+			case version != "": // without a version the managed version is used
+				addVersion(versions, name, version)
+			}
+		}
+	}
+	return versions, nil
+}
@@ -0,0 +1,140 @@
+package deckard
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	REFERENCE_CVE  = "CVE"
+	REFERENCE_GHSA = "GHSA"
+)
+
+// Reference is a security advisory identifier mentioned in a commit message.
+type Reference struct {
+	Kind string // one of the REFERENCE_ constants
+	ID   string
+}
+
+// URL returns the web page of the advisory.
+func (r Reference) URL() string {
+	switch r.Kind {
+	case REFERENCE_CVE:
+		return "https://nvd.nist.gov/vuln/detail/" + r.ID
+	case REFERENCE_GHSA:
+		return "https://github.com/advisories/" + r.ID
+	}
+	return ""
+}
+
+var referencePatterns = map[string]*regexp.Regexp{
+	REFERENCE_CVE:  regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`),
+	REFERENCE_GHSA: regexp.MustCompile(`(?i)\bGHSA(-[23456789cfghjmpqrvwx]{4}){3}\b`),
+}
+
+// extractReferences returns the unique advisory identifiers of the text, sorted.
+func extractReferences(text string) []Reference {
+	seen := make(map[string]bool)
+	refs := make([]Reference, 0)
+	for kind, re := range referencePatterns {
+		for _, match := range re.FindAllString(text, -1) {
+			id := strings.ToUpper(match)
+			if kind == REFERENCE_GHSA {
+				id = "GHSA" + strings.ToLower(match[4:])
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			refs = append(refs, Reference{Kind: kind, ID: id})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].ID < refs[j].ID
+	})
+	return refs
+}
+
+// keywords that are scored if not overwritten in the config
+var defaultMessageKeywords = map[string]int{
+	"security":          40,
+	"vulnerability":     40,
+	"vulnerable":        30,
+	"exploit":           40,
+	"bypass":            30,
+	"hotfix":            20,
+	"revert":            10,
+	"xss":               30,
+	"csrf":              30,
+	"injection":         30,
+	"overflow":          20,
+	"use after free":    30,
+	"denial of service": 20,
+	"rce":               40,
+	"privilege":         20,
+	"sanitize":          10,
+	"embargo":           40,
+}
+
+type messageKeyword struct {
+	keyword string
+	re      *regexp.Regexp
+	weight  int
+}
+
+// compileMessageKeywords merges the configured keyword weights into the
+// defaults, a weight of 0 disables a keyword.
+func compileMessageKeywords(configured map[string]int) ([]*messageKeyword, error) {
+	weights := make(map[string]int)
+	for keyword, weight := range defaultMessageKeywords {
+		weights[keyword] = weight
+	}
+	for keyword, weight := range configured {
+		if weight < 0 || weight > maxSlatScore {
+			return nil, fmt.Errorf("keyword weight for '%s' must be between 0 and %d, is %d", keyword, maxSlatScore, weight)
+		}
+		weights[strings.ToLower(keyword)] = weight
+	}
+
+	keywords := make([]*messageKeyword, 0, len(weights))
+	for keyword, weight := range weights {
+		if weight == 0 {
+			continue
+		}
+		words := strings.Fields(keyword)
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		re, err := regexp.Compile(`(?i)\b` + strings.Join(words, `\s+`) + `\b`)
+		if err != nil {
+			return nil, err
+		}
+		keywords = append(keywords, &messageKeyword{keyword: keyword, re: re, weight: weight})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		return keywords[i].keyword < keywords[j].keyword
+	})
+	return keywords, nil
+}
+
+// messageReasons scores the advisory references and keywords of the commit message.
+func messageReasons(conf ConfigMessages, keywords []*messageKeyword, commit *Commit) []Reason {
+	reasons := make([]Reason, 0)
+	if len(commit.References) > 0 && conf.ReferenceWeight > 0 {
+		ids := make([]string, 0, len(commit.References))
+		for _, ref := range commit.References {
+			ids = append(ids, ref.ID)
+		}
+		reasons = append(reasons, Reason{Rule: "security advisory", Match: strings.Join(ids, ", "), Contribution: conf.ReferenceWeight})
+	}
+
+	for _, keyword := range keywords {
+		line, ok := matchLine([]*regexp.Regexp{keyword.re}, commit.Subject, commit.Message)
+		if ok {
+			reasons = append(reasons, Reason{Rule: "keyword " + keyword.keyword, Match: shorten(line, 80), Contribution: keyword.weight})
+		}
+	}
+	return reasons
+}
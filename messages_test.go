@@ -0,0 +1,108 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExtractReferences(t *testing.T) {
+	tc := []struct {
+		desc     string
+		text     string
+		expected []Reference
+	}{
+		{desc: "no references", text: "fix typo in README", expected: []Reference{}},
+		{
+			desc:     "cve",
+			text:     "Fix path traversal (CVE-2023-12345)",
+			expected: []Reference{{Kind: REFERENCE_CVE, ID: "CVE-2023-12345"}},
+		},
+		{
+			desc:     "ghsa is normalized",
+			text:     "See GHSA-XVCH-5GV4-984H for details",
+			expected: []Reference{{Kind: REFERENCE_GHSA, ID: "GHSA-xvch-5gv4-984h"}},
+		},
+		{
+			desc: "duplicates are removed and sorted",
+			text: "cve-2021-44228\nFixes CVE-2021-44228 and CVE-2021-45046, GHSA-jfh8-c2jp-5v3q",
+			expected: []Reference{
+				{Kind: REFERENCE_CVE, ID: "CVE-2021-44228"},
+				{Kind: REFERENCE_CVE, ID: "CVE-2021-45046"},
+				{Kind: REFERENCE_GHSA, ID: "GHSA-jfh8-c2jp-5v3q"},
+			},
+		},
+		{desc: "short cve number", text: "CVE-2021-123", expected: []Reference{}},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			refs := extractReferences(c.text)
+			if diff := cmp.Diff(refs, c.expected); diff != "" {
+				t.Errorf("unexpected references: %s", diff)
+			}
+		})
+	}
+}
+
+func TestMessageReasons(t *testing.T) {
+	keywords, err := compileMessageKeywords(map[string]int{"revert": 0, "backdoor": 70})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	conf := ConfigMessages{ReferenceWeight: 60}
+
+	tc := []struct {
+		desc     string
+		commit   *Commit
+		expected []Reason
+	}{
+		{desc: "nothing matches", commit: &Commit{Subject: "add feature"}, expected: []Reason{}},
+		{
+			desc: "references and keywords",
+			commit: &Commit{
+				Subject:    "Hotfix for auth bypass",
+				Message:    "Fixes CVE-2024-3094",
+				References: []Reference{{Kind: REFERENCE_CVE, ID: "CVE-2024-3094"}},
+			},
+			expected: []Reason{
+				{Rule: "security advisory", Match: "CVE-2024-3094", Contribution: 60},
+				{Rule: "keyword bypass", Match: "Hotfix for auth bypass", Contribution: 30},
+				{Rule: "keyword hotfix", Match: "Hotfix for auth bypass", Contribution: 20},
+			},
+		},
+		{desc: "keyword must be a whole word", commit: &Commit{Subject: "update securityContext"}, expected: []Reason{}},
+		{
+			desc:     "multi word keyword across line breaks",
+			commit:   &Commit{Subject: "fix crash", Message: "a use after\nfree in the parser"},
+			expected: []Reason{},
+		},
+		{
+			desc:     "multi word keyword",
+			commit:   &Commit{Subject: "fix use  after free"},
+			expected: []Reason{{Rule: "keyword use after free", Match: "fix use  after free", Contribution: 30}},
+		},
+		{desc: "disabled keyword", commit: &Commit{Subject: "Revert \"add feature\""}, expected: []Reason{}},
+		{
+			desc:     "configured keyword",
+			commit:   &Commit{Subject: "remove backdoor"},
+			expected: []Reason{{Rule: "keyword backdoor", Match: "remove backdoor", Contribution: 70}},
+		},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			reasons := messageReasons(conf, keywords, c.commit)
+			if diff := cmp.Diff(reasons, c.expected); diff != "" {
+				t.Errorf("unexpected reasons: %s", diff)
+			}
+		})
+	}
+}
+
+func TestCompileMessageKeywordsErrors(t *testing.T) {
+	_, err := compileMessageKeywords(map[string]int{"security": 101})
+	if err == nil {
+		t.Errorf("expected error for weight > %d", maxSlatScore)
+	}
+}
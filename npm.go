@@ -0,0 +1,138 @@
+package deckard
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+// npmAnalyzer handles package.json and the npm and yarn lock files.
+type npmAnalyzer struct{}
+
+func (npmAnalyzer) handles(file string) bool {
+	switch path.Base(file) {
+	case "package.json", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock":
+		return !strings.Contains(file, "node_modules/")
+	}
+	return false
+}
+
+func (npmAnalyzer) ecosystem() string {
+	return "npm"
+}
+
+func (npmAnalyzer) diff(manifest, before, after string) ([]DependencyChange, error) {
+	var parse func(string) (map[string]string, error)
+	switch path.Base(manifest) {
+	case "package.json":
+		parse = parsePackageJSON
+	case "yarn.lock":
+		parse = parseYarnLock
+	default:
+		parse = parsePackageLock
+	}
+
+	versionsBefore, err := parse(before)
+	if err != nil {
+		return nil, err
+	}
+	versionsAfter, err := parse(after)
+	if err != nil {
+		return nil, err
+	}
+	return diffVersions(manifest, versionsBefore, versionsAfter), nil
+}
+
+type packageJSON struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+func parsePackageJSON(content string) (map[string]string, error) {
+	versions := make(map[string]string)
+	if strings.TrimSpace(content) == "" {
+		return versions, nil
+	}
+
+	var pkg packageJSON
+	err := json.Unmarshal([]byte(content), &pkg)
+	if err != nil {
+		return nil, err
+	}
+	for _, deps := range []map[string]string{pkg.PeerDependencies, pkg.OptionalDependencies, pkg.DevDependencies, pkg.Dependencies} {
+		for name, version := range deps {
+			versions[name] = version
+		}
+	}
+	return versions, nil
+}
+
+type packageLock struct {
+	// lockfileVersion >= 2
+	Packages map[string]struct {
+		Version string `json:"version"`
+	} `json:"packages"`
+	// lockfileVersion 1
+	Dependencies map[string]struct {
+		Version string `json:"version"`
+	} `json:"dependencies"`
+}
+
+func parsePackageLock(content string) (map[string]string, error) {
+	versions := make(map[string]string)
+	if strings.TrimSpace(content) == "" {
+		return versions, nil
+	}
+
+	var lock packageLock
+	err := json.Unmarshal([]byte(content), &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lock.Packages) > 0 {
+		for pkgPath, pkg := range lock.Packages {
+			i := strings.LastIndex(pkgPath, "node_modules/")
+			if i < 0 {
+				continue // the root package or a workspace
+			}
+			addVersion(versions, pkgPath[i+len("node_modules/"):], pkg.Version)
+		}
+		return versions, nil
+	}
+	for name, dep := range lock.Dependencies {
+		addVersion(versions, name, dep.Version)
+	}
+	return versions, nil
+}
+
+// parseYarnLock parses classic and berry yarn.lock files.
+func parseYarnLock(content string) (map[string]string, error) {
+	versions := make(map[string]string)
+	name := ""
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			// header: "@babel/core@^7.0.0", "@babel/core@^7.1.0":
+			spec := strings.Split(strings.TrimSuffix(line, ":"), ",")[0]
+			spec = strings.Trim(strings.TrimSpace(spec), `"`)
+			name = ""
+			if at := strings.LastIndex(spec, "@"); at > 0 {
+				name = spec[:at]
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if name != "" && strings.HasPrefix(trimmed, "version") {
+			version := strings.TrimPrefix(trimmed, "version")
+			version = strings.Trim(strings.TrimPrefix(strings.TrimSpace(version), ":"), ` "`)
+			addVersion(versions, name, version)
+			name = ""
+		}
+	}
+	return versions, nil
+}
@@ -0,0 +1,139 @@
+package deckard
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// file extensions and names to language, used to select the code patterns
+// that apply to a file
+var languageExtensions = map[string]string{
+	".go":   "go",
+	".rs":   "rust",
+	".py":   "python",
+	".js":   "javascript",
+	".mjs":  "javascript",
+	".cjs":  "javascript",
+	".jsx":  "javascript",
+	".ts":   "javascript",
+	".tsx":  "javascript",
+	".dart": "dart",
+	".java": "java",
+	".kt":   "java",
+	".sh":   "shell",
+	".bash": "shell",
+	".zsh":  "shell",
+	".mk":   "make",
+	".yml":  "yaml",
+	".yaml": "yaml",
+}
+
+var languageFileNames = map[string]string{
+	"Dockerfile":  "docker",
+	"Makefile":    "make",
+	"GNUmakefile": "make",
+}
+
+func fileLanguage(file string) string {
+	base := path.Base(file)
+	if lang, ok := languageFileNames[base]; ok {
+		return lang
+	}
+	if strings.HasPrefix(base, "Dockerfile.") || strings.HasSuffix(base, ".Dockerfile") {
+		return "docker"
+	}
+	return languageExtensions[path.Ext(base)]
+}
+
+// patterns that are checked if not overwritten by a pattern with the same name in the config
+var defaultCodePatterns = []ConfigPattern{
+	{Name: "go exec", Languages: []string{"go"}, Regex: `"os/exec"|exec\.Command(Context)?\(`, Weight: 40},
+	{Name: "go unsafe", Languages: []string{"go"}, Regex: `"unsafe"|unsafe\.Pointer`, Weight: 30},
+	{Name: "go cgo", Languages: []string{"go"}, Regex: `^\s*import\s+"C"|^\s*#cgo\s`, Weight: 30},
+	{Name: "go linkname", Languages: []string{"go"}, Regex: `^//go:linkname\s`, Weight: 40},
+	{Name: "go init", Languages: []string{"go"}, Regex: `^func init\(\)`, Weight: 20},
+	{Name: "rust unsafe", Languages: []string{"rust"}, Regex: `\bunsafe\s*(\{|fn\b|impl\b)`, Weight: 30},
+	{Name: "rust process", Languages: []string{"rust"}, Regex: `std::process::Command|\bCommand::new\(`, Weight: 40},
+	{Name: "python exec", Languages: []string{"python"}, Regex: `\bsubprocess\.|\bos\.(system|popen)\(|\b(eval|exec)\(|__import__\(`, Weight: 40},
+	{Name: "js exec", Languages: []string{"javascript"}, Regex: `child_process|\beval\(|new Function\(`, Weight: 40},
+	{Name: "dart process", Languages: []string{"dart"}, Regex: `Process\.(run|start)(Sync)?\(|dart:ffi`, Weight: 40},
+	{Name: "java exec", Languages: []string{"java"}, Regex: `Runtime\.getRuntime\(\)\.exec|new ProcessBuilder\(`, Weight: 40},
+	{Name: "pipe to shell", Languages: []string{"shell", "docker", "make", "yaml"}, Regex: `\b(curl|wget)\b[^|]*\|\s*(sudo\s+)?(ba|z)?sh\b`, Weight: 60},
+	{Name: "base64 blob", Regex: `[A-Za-z0-9+/]{200,}={0,2}`, Weight: 40},
+	{Name: "obfuscated string", Regex: `(\\x[0-9a-fA-F]{2}){8,}|(\\u[0-9a-fA-F]{4}){8,}|String\.fromCharCode\(\s*\d+\s*(,\s*\d+\s*){7,}`, Weight: 40},
+}
+
+type codePattern struct {
+	name      string
+	languages map[string]bool // empty for all languages
+	re        *regexp.Regexp
+	weight    int
+}
+
+// compileCodePatterns merges the configured patterns into the default patterns.
+func compileCodePatterns(configured []ConfigPattern) ([]*codePattern, error) {
+	merged := make([]ConfigPattern, 0, len(defaultCodePatterns)+len(configured))
+	overwritten := make(map[string]bool)
+	for _, conf := range configured {
+		overwritten[conf.Name] = true
+	}
+	for _, conf := range defaultCodePatterns {
+		if !overwritten[conf.Name] {
+			merged = append(merged, conf)
+		}
+	}
+	merged = append(merged, configured...)
+
+	patterns := make([]*codePattern, 0, len(merged))
+	for _, conf := range merged {
+		if conf.Weight < 0 || conf.Weight > maxSlatScore {
+			return nil, fmt.Errorf("pattern '%s': weight must be between 0 and %d, is %d", conf.Name, maxSlatScore, conf.Weight)
+		}
+		if conf.Weight == 0 { // disabled
+			continue
+		}
+		re, err := regexp.Compile(conf.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("pattern '%s': illegal regex '%s': %w", conf.Name, conf.Regex, err)
+		}
+		languages := make(map[string]bool)
+		for _, lang := range conf.Languages {
+			languages[lang] = true
+		}
+		patterns = append(patterns, &codePattern{name: conf.Name, languages: languages, re: re, weight: conf.Weight})
+	}
+	return patterns, nil
+}
+
+func (p *codePattern) appliesTo(lang string) bool {
+	return len(p.languages) == 0 || p.languages[lang]
+}
+
+// scanCode matches the code patterns against the added lines of a patch.
+func scanCode(patterns []*codePattern, patches []FilePatch) map[*codePattern][]Finding {
+	findings := make(map[*codePattern][]Finding)
+	for _, patch := range patches {
+		lang := fileLanguage(patch.File)
+		for _, pattern := range patterns {
+			if !pattern.appliesTo(lang) {
+				continue
+			}
+			for _, line := range patch.Added {
+				if pattern.re.MatchString(line.Text) {
+					findings[pattern] = append(findings[pattern], Finding{File: patch.File, Line: line.Num, Kind: pattern.name, Text: shorten(strings.TrimSpace(line.Text), 80)})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func shorten(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
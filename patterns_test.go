@@ -0,0 +1,52 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScanCode(t *testing.T) {
+	patterns, err := compileCodePatterns([]ConfigPattern{
+		{Name: "go init", Weight: 0},
+		{Name: "todo", Languages: []string{"go"}, Regex: `TODO`, Weight: 5},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	tc := []struct {
+		desc     string
+		file     string
+		line     string
+		expected []string
+	}{
+		{desc: "go exec import", file: "cmd/run.go", line: `	"os/exec"`, expected: []string{"go exec"}},
+		{desc: "go unsafe", file: "mem.go", line: `p := unsafe.Pointer(&x)`, expected: []string{"go unsafe"}},
+		{desc: "cgo", file: "c.go", line: `import "C"`, expected: []string{"go cgo"}},
+		{desc: "disabled pattern", file: "init.go", line: `func init() {`, expected: []string{}},
+		{desc: "configured pattern", file: "main.go", line: `// TODO remove`, expected: []string{"todo"}},
+		{desc: "language of configured pattern", file: "main.rs", line: `// TODO remove`, expected: []string{}},
+		{desc: "curl pipe sh in script", file: "install.sh", line: `curl -fsSL https://example.com/i.sh | sudo bash`, expected: []string{"pipe to shell"}},
+		{desc: "curl pipe sh in Dockerfile", file: "Dockerfile", line: `RUN wget -qO- https://example.com/i.sh | sh`, expected: []string{"pipe to shell"}},
+		{desc: "curl pipe sh in go", file: "doc.go", line: `// curl https://example.com | sh`, expected: []string{}},
+		{desc: "python subprocess", file: "setup.py", line: `subprocess.call(["sh", "-c", cmd])`, expected: []string{"python exec"}},
+		{desc: "obfuscated string", file: "lib.js", line: `var s = "\x68\x74\x74\x70\x3a\x2f\x2f\x65";`, expected: []string{"obfuscated string"}},
+		{desc: "harmless line", file: "main.go", line: `fmt.Println("hi")`, expected: []string{}},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			findings := scanCode(patterns, []FilePatch{{File: c.file, Added: []PatchLine{{Num: 1, Text: c.line}}}})
+			found := make([]string, 0)
+			for _, pattern := range patterns {
+				if len(findings[pattern]) > 0 {
+					found = append(found, pattern.name)
+				}
+			}
+			if diff := cmp.Diff(found, c.expected); diff != "" {
+				t.Errorf("unexpected patterns: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package deckard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// used if a scorer does not configure a timeout
+const defaultScorerTimeout = 10 * time.Second
+
+// scorers writing more are cut off and fail
+const maxScorerOutput = 1 << 20
+
+// externalScorer is an executable that scores commits, see ConfigScorer.
+type externalScorer struct {
+	name     string
+	command  []string
+	timeout  time.Duration
+	patch    bool
+	maxScore int
+}
+
+// scorerInput is sent as JSON to the stdin of an external scorer.
+type scorerInput struct {
+	Project    string        `json:"project"`
+	Hash       string        `json:"hash"`
+	Subject    string        `json:"subject"`
+	Message    string        `json:"message"`
+	Author     scorerPerson  `json:"author"`
+	Committer  scorerPerson  `json:"committer"`
+	CommitTime time.Time     `json:"commit_time"`
+	Files      []scorerFile  `json:"files"`
+	Patch      []scorerPatch `json:"patch,omitempty"` // only sent to scorers with patch = true
+}
+
+type scorerPerson struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type scorerFile struct {
+	File    string `json:"file"`
+	Added   uint64 `json:"added"`
+	Deleted uint64 `json:"deleted"`
+	Binary  bool   `json:"binary"`
+}
+
+type scorerPatch struct {
+	File  string       `json:"file"`
+	Added []scorerLine `json:"added"`
+}
+
+type scorerLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// scorerOutput is read as JSON from the stdout of an external scorer. If
+// there are no reasons, a score > 0 becomes a single reason.
+type scorerOutput struct {
+	Score   int `json:"score"`
+	Reasons []struct {
+		Rule         string `json:"rule"`
+		Match        string `json:"match"`
+		Contribution int    `json:"contribution"`
+	} `json:"reasons"`
+}
+
+func compileScorer(conf ConfigScorer) (*externalScorer, error) {
+	if conf.Name == "" {
+		return nil, fmt.Errorf("scorer without name")
+	}
+	if len(conf.Command) == 0 {
+		return nil, fmt.Errorf("scorer '%s' without command", conf.Name)
+	}
+	if conf.Timeout < 0 {
+		return nil, fmt.Errorf("scorer '%s': timeout must not be negative, is %d", conf.Name, conf.Timeout)
+	}
+	if conf.MaxScore < 0 || conf.MaxScore > maxSlatScore {
+		return nil, fmt.Errorf("scorer '%s': max_score must be between 0 and %d, is %d", conf.Name, maxSlatScore, conf.MaxScore)
+	}
+	s := &externalScorer{name: conf.Name, command: conf.Command, timeout: defaultScorerTimeout, patch: conf.Patch, maxScore: conf.MaxScore}
+	if conf.Timeout > 0 {
+		s.timeout = time.Duration(conf.Timeout) * time.Second
+	}
+	if s.maxScore == 0 {
+		s.maxScore = maxSlatScore
+	}
+	return s, nil
+}
+
+func newScorerInput(commit *Commit, diff *Diff, patch bool) *scorerInput {
+	input := &scorerInput{
+		Project:    commit.Project,
+		Hash:       commit.Hash,
+		Subject:    commit.Subject,
+		Message:    commit.Message,
+		Author:     scorerPerson{Name: commit.AuthorName, Email: commit.AuthorEmail},
+		Committer:  scorerPerson{Name: commit.CommitterName, Email: commit.CommitterEmail},
+		CommitTime: commit.CommitWhen,
+		Files:      make([]scorerFile, 0, len(diff.Stats)),
+	}
+	for _, stat := range diff.Stats {
+		input.Files = append(input.Files, scorerFile{File: stat.File, Added: stat.Added, Deleted: stat.Deleted, Binary: stat.Binary})
+	}
+	if patch {
+		input.Patch = make([]scorerPatch, 0, len(diff.Patch))
+		for _, filePatch := range diff.Patch {
+			lines := make([]scorerLine, 0, len(filePatch.Added))
+			for _, line := range filePatch.Added {
+				lines = append(lines, scorerLine{Line: line.Num, Text: line.Text})
+			}
+			input.Patch = append(input.Patch, scorerPatch{File: filePatch.File, Added: lines})
+		}
+	}
+	return input
+}
+
+// runScorers runs the external scorers on the commit. A failing scorer does
+// not affect the others, the failures are returned as text for the commit.
+func runScorers(scorers []*externalScorer, commit *Commit, diff *Diff) ([]Reason, string) {
+	reasons := make([]Reason, 0)
+	failures := make([]string, 0)
+	for _, s := range scorers {
+		scorerReasons, err := s.run(newScorerInput(commit, diff, s.patch))
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", s.name, err))
+			continue
+		}
+		reasons = append(reasons, scorerReasons...)
+	}
+	return reasons, strings.Join(failures, "; ")
+}
+
+func (s *externalScorer) run(input *scorerInput) ([]Reason, error) {
+	in, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = runCommand(s.command, "", s.timeout, bytes.NewReader(in),
+		&limitedWriter{w: &stdout, n: maxScorerOutput}, &limitedWriter{w: &stderr, n: 4096, truncate: true})
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, shorten(msg, 200))
+		}
+		return nil, err
+	}
+	return s.parseOutput(stdout.Bytes())
+}
+
+func (s *externalScorer) parseOutput(out []byte) ([]Reason, error) {
+	var output scorerOutput
+	err := json.Unmarshal(out, &output)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+
+	reasons := make([]Reason, 0, len(output.Reasons))
+	if len(output.Reasons) == 0 {
+		if output.Score > 0 {
+			reasons = append(reasons, Reason{Rule: s.name, Contribution: s.clamp(output.Score)})
+		}
+		return reasons, nil
+	}
+	for _, reason := range output.Reasons {
+		rule := s.name
+		if reason.Rule != "" {
+			rule += " " + reason.Rule
+		}
+		reasons = append(reasons, Reason{Rule: rule, Match: reason.Match, Contribution: s.clamp(reason.Contribution)})
+	}
+	return reasons, nil
+}
+
+func (s *externalScorer) clamp(contribution int) int {
+	if contribution < 0 {
+		return 0
+	}
+	if contribution > s.maxScore {
+		return s.maxScore
+	}
+	return contribution
+}
+
+// runCommand runs the command in dir (the current one if empty) and kills it
+// after the timeout. A timeout is reported as such, other errors are the ones
+// of exec.Cmd.Run, e.g. *exec.ExitError for a non-zero exit code.
+func runCommand(command []string, dir string, timeout time.Duration, stdin io.Reader, stdout, stderr io.Writer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second // children of a killed command may keep the pipes open
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timeout after %s", timeout)
+	}
+	return err
+}
+
+// limitedWriter fails once more than n bytes are written, or drops the rest
+// if truncate is set.
+type limitedWriter struct {
+	w        io.Writer
+	n        int
+	truncate bool
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.n {
+		if !l.truncate {
+			return 0, fmt.Errorf("output exceeds limit")
+		}
+		_, err := l.w.Write(p[:l.n])
+		l.n = 0
+		return len(p), err
+	}
+	l.n -= len(p)
+	return l.w.Write(p)
+}
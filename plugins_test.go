@@ -0,0 +1,97 @@
+package deckard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompileScorer(t *testing.T) {
+	tc := []struct {
+		conf ConfigScorer
+		err  string
+	}{
+		{ConfigScorer{Command: []string{"true"}}, "scorer without name"},
+		{ConfigScorer{Name: "x"}, "scorer 'x' without command"},
+		{ConfigScorer{Name: "x", Command: []string{"true"}, Timeout: -1}, "scorer 'x': timeout must not be negative, is -1"},
+		{ConfigScorer{Name: "x", Command: []string{"true"}, MaxScore: 101}, "scorer 'x': max_score must be between 0 and 100, is 101"},
+	}
+	for _, c := range tc {
+		_, err := compileScorer(c.conf)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("expected error '%s' for %#v, got %v", c.err, c.conf, err)
+		}
+	}
+
+	s, err := compileScorer(ConfigScorer{Name: "x", Command: []string{"true"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if s.timeout != defaultScorerTimeout || s.maxScore != maxSlatScore {
+		t.Errorf("unexpected defaults: %#v", s)
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	s := &externalScorer{name: "lint", maxScore: 50}
+	tc := []struct {
+		out      string
+		expected []Reason
+	}{
+		{`{"score": 0}`, []Reason{}},
+		{`{"score": 30}`, []Reason{{Rule: "lint", Contribution: 30}}},
+		{`{"score": 80}`, []Reason{{Rule: "lint", Contribution: 50}}},
+		{`{"score": 40, "reasons": [{"rule": "eval", "match": "eval(x)", "contribution": 70}, {"contribution": -5}]}`,
+			[]Reason{{Rule: "lint eval", Match: "eval(x)", Contribution: 50}, {Rule: "lint", Contribution: 0}}},
+	}
+	for _, c := range tc {
+		reasons, err := s.parseOutput([]byte(c.out))
+		if err != nil {
+			t.Errorf("unexpected error for %s: %#v", c.out, err)
+			continue
+		}
+		if diff := cmp.Diff(reasons, c.expected); diff != "" {
+			t.Errorf("unexpected reasons for %s: %s", c.out, diff)
+		}
+	}
+
+	_, err := s.parseOutput([]byte("score: 10"))
+	if err == nil {
+		t.Errorf("expected error for invalid output")
+	}
+}
+
+func TestRunScorers(t *testing.T) {
+	scorer := func(name, script string, patch bool) *externalScorer {
+		s, err := compileScorer(ConfigScorer{Name: name, Command: []string{"sh", "-c", script}, Timeout: 1, Patch: patch})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		return s
+	}
+	commit := &Commit{Project: "prj", Hash: "abc", Subject: "subject"}
+	diff := &Diff{
+		Stats: []NumStat{{Added: 1, File: "a.go"}},
+		Patch: []FilePatch{{File: "a.go", Added: []PatchLine{{Num: 3, Text: "eval(x)"}}}},
+	}
+
+	scorers := []*externalScorer{
+		scorer("crash", "echo boom >&2; exit 3", false),
+		scorer("hash", `grep -q '"hash":"abc"' && echo '{"score": 10}'`, false),
+		scorer("nopatch", `grep -q '"patch"' && echo '{"score": 20}' || echo '{"score": 0}'`, false),
+		scorer("patch", `grep -q '"text":"eval(x)"' && echo '{"score": 30}'`, true),
+		scorer("slow", "sleep 5", false),
+		scorer("garbage", "echo not json", false),
+	}
+	reasons, failures := runScorers(scorers, commit, diff)
+	expected := []Reason{{Rule: "hash", Contribution: 10}, {Rule: "patch", Contribution: 30}}
+	if diff := cmp.Diff(reasons, expected); diff != "" {
+		t.Errorf("unexpected reasons: %s", diff)
+	}
+	for _, failure := range []string{"crash: exit status 3: boom", "slow: timeout after 1s", "garbage: invalid output"} {
+		if !strings.Contains(failures, failure) {
+			t.Errorf("expected failure '%s' in '%s'", failure, failures)
+		}
+	}
+}
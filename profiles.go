@@ -0,0 +1,144 @@
+package deckard
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	AGGREGATE_CAPPED_SUM   = "capped_sum"   // sum of all contributions, capped at 100
+	AGGREGATE_WEIGHTED_SUM = "weighted_sum" // strongest reason counts fully, every further one less
+	AGGREGATE_MAX          = "max"          // strongest reason only
+)
+
+// used if a weighted_sum profile does not configure a decay
+const defaultProfileDecay = 0.5
+
+// scoringProfile adjusts the reasons of a project's commits and aggregates
+// them to the slat score.
+type scoringProfile struct {
+	aggregation string
+	decay       float64
+	weights     map[string]int // weight per reason rule
+}
+
+// used for projects without a profile and without weights
+var defaultProfile = &scoringProfile{aggregation: AGGREGATE_CAPPED_SUM}
+
+// compileProfiles returns the scoring profile of every project that selects
+// a profile or overrides weights.
+func compileProfiles(config *Config) (map[string]*scoringProfile, error) {
+	profiles := make(map[string]*scoringProfile)
+	for prj, conf := range config.Projects {
+		if conf.Profile == "" && len(conf.Weights) == 0 {
+			continue
+		}
+		profileConf := ConfigProfile{}
+		if conf.Profile != "" {
+			var ok bool
+			profileConf, ok = config.Profiles[conf.Profile]
+			if !ok {
+				return nil, fmt.Errorf("project '%s': unknown profile '%s'", prj, conf.Profile)
+			}
+		}
+		profile, err := compileProfile(profileConf, conf.Weights)
+		if err != nil {
+			if conf.Profile != "" {
+				return nil, fmt.Errorf("project '%s', profile '%s': %w", prj, conf.Profile, err)
+			}
+			return nil, fmt.Errorf("project '%s': %w", prj, err)
+		}
+		profiles[prj] = profile
+	}
+	return profiles, nil
+}
+
+// compileProfile compiles the profile, the project's weights override the
+// ones of the profile.
+func compileProfile(conf ConfigProfile, projectWeights map[string]int) (*scoringProfile, error) {
+	p := &scoringProfile{aggregation: conf.Aggregation, decay: conf.Decay, weights: make(map[string]int)}
+	switch p.aggregation {
+	case "":
+		p.aggregation = AGGREGATE_CAPPED_SUM
+	case AGGREGATE_CAPPED_SUM, AGGREGATE_WEIGHTED_SUM, AGGREGATE_MAX:
+	default:
+		return nil, fmt.Errorf("illegal aggregation '%s', must be %s, %s or %s", conf.Aggregation, AGGREGATE_CAPPED_SUM, AGGREGATE_WEIGHTED_SUM, AGGREGATE_MAX)
+	}
+	if p.decay < 0 || p.decay > 1 {
+		return nil, fmt.Errorf("decay must be between 0 and 1, is %g", p.decay)
+	}
+	if p.decay == 0 {
+		p.decay = defaultProfileDecay
+	}
+	for _, weights := range []map[string]int{conf.Weights, projectWeights} {
+		for rule, weight := range weights {
+			if weight < 0 || weight > maxSlatScore {
+				return nil, fmt.Errorf("weight for '%s' must be between 0 and %d, is %d", rule, maxSlatScore, weight)
+			}
+			p.weights[rule] = weight
+		}
+	}
+	return p, nil
+}
+
+// profile returns the scoring profile of the project.
+func (s *slatScorer) profile(project string) *scoringProfile {
+	if p, ok := s.profiles[project]; ok {
+		return p
+	}
+	return defaultProfile
+}
+
+// applyWeights replaces the contributions of the reasons whose rule has a
+// weight in the profile. Reasons of rules with weight 0 are dropped. Leaked
+// secrets are kept as they are.
+func (p *scoringProfile) applyWeights(reasons []Reason) []Reason {
+	if len(p.weights) == 0 {
+		return reasons
+	}
+	weighted := make([]Reason, 0, len(reasons))
+	for _, reason := range reasons {
+		if weight, ok := p.weights[reason.Rule]; ok && !isSecretReason(reason) {
+			if weight == 0 {
+				continue
+			}
+			reason.Contribution = weight
+		}
+		weighted = append(weighted, reason)
+	}
+	return weighted
+}
+
+// aggregate combines the contributions of the reasons to a slat score.
+func (p *scoringProfile) aggregate(reasons []Reason) int {
+	score := 0
+	switch p.aggregation {
+	case AGGREGATE_MAX:
+		for _, reason := range reasons {
+			if reason.Contribution > score {
+				score = reason.Contribution
+			}
+		}
+	case AGGREGATE_WEIGHTED_SUM:
+		contributions := make([]int, 0, len(reasons))
+		for _, reason := range reasons {
+			contributions = append(contributions, reason.Contribution)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(contributions)))
+		sum := 0.0
+		factor := 1.0
+		for _, contribution := range contributions {
+			sum += float64(contribution) * factor
+			factor *= p.decay
+		}
+		score = int(sum + 0.5)
+	default:
+		for _, reason := range reasons {
+			score += reason.Contribution
+		}
+	}
+	if score > maxSlatScore {
+		return maxSlatScore
+	}
+	return score
+}
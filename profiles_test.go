@@ -0,0 +1,91 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompileProfiles(t *testing.T) {
+	config := &Config{
+		Projects: map[string]ConfigProject{
+			"flutter": {Profile: "relaxed", Weights: map[string]int{"build ci": 10}},
+			"crypto":  {Weights: map[string]int{"keyword revert": 0}},
+			"plain":   {},
+		},
+		Profiles: map[string]ConfigProfile{
+			"relaxed": {Aggregation: AGGREGATE_WEIGHTED_SUM, Weights: map[string]int{"build ci": 30, "new author": 20}},
+		},
+	}
+	profiles, err := compileProfiles(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	expected := map[string]*scoringProfile{
+		"flutter": {aggregation: AGGREGATE_WEIGHTED_SUM, decay: defaultProfileDecay, weights: map[string]int{"build ci": 10, "new author": 20}},
+		"crypto":  {aggregation: AGGREGATE_CAPPED_SUM, decay: defaultProfileDecay, weights: map[string]int{"keyword revert": 0}},
+	}
+	if diff := cmp.Diff(profiles, expected, cmp.AllowUnexported(scoringProfile{})); diff != "" {
+		t.Errorf("unexpected profiles: %s", diff)
+	}
+
+	tc := []struct {
+		project ConfigProject
+		profile ConfigProfile
+		err     string
+	}{
+		{ConfigProject{Profile: "missing"}, ConfigProfile{}, "project 'p': unknown profile 'missing'"},
+		{ConfigProject{Profile: "x"}, ConfigProfile{Aggregation: "avg"}, "project 'p', profile 'x': illegal aggregation 'avg', must be capped_sum, weighted_sum or max"},
+		{ConfigProject{Profile: "x"}, ConfigProfile{Decay: 1.5}, "project 'p', profile 'x': decay must be between 0 and 1, is 1.5"},
+		{ConfigProject{Weights: map[string]int{"build ci": 101}}, ConfigProfile{}, "project 'p': weight for 'build ci' must be between 0 and 100, is 101"},
+	}
+	for _, c := range tc {
+		_, err := compileProfiles(&Config{Projects: map[string]ConfigProject{"p": c.project}, Profiles: map[string]ConfigProfile{"x": c.profile}})
+		if err == nil || err.Error() != c.err {
+			t.Errorf("expected error '%s', got %v", c.err, err)
+		}
+	}
+}
+
+func TestProfileAggregate(t *testing.T) {
+	reasons := []Reason{{Rule: "a", Contribution: 20}, {Rule: "b", Contribution: 60}, {Rule: "c", Contribution: 40}}
+	tc := []struct {
+		profile  *scoringProfile
+		reasons  []Reason
+		expected int
+	}{
+		{defaultProfile, reasons, 100},
+		{defaultProfile, reasons[:1], 20},
+		{defaultProfile, []Reason{}, 0},
+		{&scoringProfile{aggregation: AGGREGATE_MAX}, reasons, 60},
+		{&scoringProfile{aggregation: AGGREGATE_WEIGHTED_SUM, decay: 0.5}, reasons, 85},  // 60 + 20 + 5
+		{&scoringProfile{aggregation: AGGREGATE_WEIGHTED_SUM, decay: 0.25}, reasons, 71}, // 60 + 10 + 1.25
+		{&scoringProfile{aggregation: AGGREGATE_WEIGHTED_SUM, decay: 1}, reasons, 100},
+	}
+	for _, c := range tc {
+		score := c.profile.aggregate(c.reasons)
+		if score != c.expected {
+			t.Errorf("expected %d for %s, got %d", c.expected, c.profile.aggregation, score)
+		}
+	}
+}
+
+func TestProfileApplyWeights(t *testing.T) {
+	profile := &scoringProfile{weights: map[string]int{"build ci": 10, "keyword revert": 0, "secret github token": 0, "secret private key": 20}}
+	reasons := []Reason{
+		{Rule: "build ci", Match: ".github/workflows/ci.yml", Contribution: 40},
+		{Rule: "keyword revert", Match: "Revert x", Contribution: 20},
+		{Rule: "new author", Match: "eve", Contribution: 50},
+		{Rule: "secret github token", Match: "ci.env:3 ghp_****", Contribution: 100},
+		{Rule: "secret private key", Match: "id_rsa:1", Contribution: 100},
+	}
+	expected := []Reason{
+		{Rule: "build ci", Match: ".github/workflows/ci.yml", Contribution: 10},
+		{Rule: "new author", Match: "eve", Contribution: 50},
+		{Rule: "secret github token", Match: "ci.env:3 ghp_****", Contribution: 100},
+		{Rule: "secret private key", Match: "id_rsa:1", Contribution: 100},
+	}
+	if diff := cmp.Diff(profile.applyWeights(reasons), expected); diff != "" {
+		t.Errorf("unexpected reasons: %s", diff)
+	}
+}
@@ -0,0 +1,135 @@
+package deckard
+
+import (
+	"path"
+	"strings"
+)
+
+// pubAnalyzer handles pubspec.yaml and pubspec.lock of dart/flutter projects.
+type pubAnalyzer struct{}
+
+func (pubAnalyzer) handles(file string) bool {
+	base := path.Base(file)
+	return base == "pubspec.yaml" || base == "pubspec.lock"
+}
+
+func (pubAnalyzer) ecosystem() string {
+	return "Pub"
+}
+
+func (pubAnalyzer) diff(manifest, before, after string) ([]DependencyChange, error) {
+	parse := parsePubspec
+	if path.Base(manifest) == "pubspec.lock" {
+		parse = parsePubspecLock
+	}
+	return diffVersions(manifest, parse(before), parse(after)), nil
+}
+
+var pubDependencySections = []string{"dependencies", "dev_dependencies", "dependency_overrides"}
+
+func parsePubspec(content string) map[string]string {
+	doc := parseSimpleYAML(content)
+	versions := make(map[string]string)
+	for _, section := range pubDependencySections {
+		deps, _ := doc[section].(map[string]interface{})
+		for name, spec := range deps {
+			versions[name] = pubVersion(spec)
+		}
+	}
+	return versions
+}
+
+// pubVersion returns the version of a dependency that is either a plain
+// version constraint or a map with a version, git, path or sdk source.
+func pubVersion(spec interface{}) string {
+	switch s := spec.(type) {
+	case string:
+		return s
+	case map[string]interface{}:
+		if version, ok := s["version"].(string); ok {
+			return version
+		}
+		switch git := s["git"].(type) {
+		case string:
+			return "git " + git
+		case map[string]interface{}:
+			url, _ := git["url"].(string)
+			if ref, ok := git["ref"].(string); ok {
+				return "git " + url + "#" + ref
+			}
+			return "git " + url
+		}
+		if p, ok := s["path"].(string); ok {
+			return "path " + p
+		}
+		if sdk, ok := s["sdk"].(string); ok {
+			return "sdk " + sdk
+		}
+	}
+	return ""
+}
+
+func parsePubspecLock(content string) map[string]string {
+	doc := parseSimpleYAML(content)
+	versions := make(map[string]string)
+	packages, _ := doc["packages"].(map[string]interface{})
+	for name, pkg := range packages {
+		if pkgMap, ok := pkg.(map[string]interface{}); ok {
+			version, _ := pkgMap["version"].(string)
+			versions[name] = version
+		}
+	}
+	return versions
+}
+
+// parseSimpleYAML parses the block mapping subset of YAML that is used by
+// pubspec files into nested maps. Scalars are returned as strings, lists,
+// anchors and flow style collections are not supported and skipped.
+func parseSimpleYAML(content string) map[string]interface{} {
+	type level struct {
+		indent int
+		values map[string]interface{}
+	}
+
+	root := make(map[string]interface{})
+	stack := []level{{indent: -1, values: root}}
+	for _, rawLine := range strings.Split(content, "\n") {
+		line := stripYAMLComment(rawLine)
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		colon := strings.Index(trimmed, ":")
+		if colon < 0 {
+			continue
+		}
+		key := strings.Trim(trimmed[:colon], `"'`)
+		value := strings.Trim(strings.TrimSpace(trimmed[colon+1:]), `"'`)
+
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].values
+
+		if value == "" {
+			child := make(map[string]interface{})
+			parent[key] = child
+			stack = append(stack, level{indent: indent, values: child})
+			continue
+		}
+		parent[key] = value
+	}
+	return root
+}
+
+func stripYAMLComment(line string) string {
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return ""
+	}
+	if i := strings.Index(line, " #"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
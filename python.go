@@ -0,0 +1,188 @@
+package deckard
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// pythonAnalyzer handles requirements files, pyproject.toml (poetry and
+// PEP 621) and poetry.lock.
+type pythonAnalyzer struct{}
+
+func (pythonAnalyzer) handles(file string) bool {
+	base := path.Base(file)
+	if base == "pyproject.toml" || base == "poetry.lock" {
+		return true
+	}
+	if path.Ext(base) != ".txt" {
+		return false
+	}
+	return strings.HasPrefix(base, "requirements") || path.Base(path.Dir(file)) == "requirements"
+}
+
+func (pythonAnalyzer) ecosystem() string {
+	return "PyPI"
+}
+
+func (pythonAnalyzer) diff(manifest, before, after string) ([]DependencyChange, error) {
+	var parse func(string) (map[string]string, error)
+	switch path.Base(manifest) {
+	case "pyproject.toml":
+		parse = parsePyproject
+	case "poetry.lock":
+		parse = parsePoetryLock
+	default:
+		parse = parseRequirements
+	}
+
+	versionsBefore, err := parse(before)
+	if err != nil {
+		return nil, err
+	}
+	versionsAfter, err := parse(after)
+	if err != nil {
+		return nil, err
+	}
+	return diffVersions(manifest, versionsBefore, versionsAfter), nil
+}
+
+// name, optional extras and the version specifier of a PEP 508 requirement
+var pythonRequirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(\(?[^;]*)`)
+
+func parseRequirements(content string) (map[string]string, error) {
+	versions := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue // options like -r other.txt or -e .
+		}
+		name, version := parsePythonRequirement(line)
+		if name != "" {
+			versions[name] = version
+		}
+	}
+	return versions, nil
+}
+
+func parsePythonRequirement(requirement string) (string, string) {
+	match := pythonRequirement.FindStringSubmatch(strings.TrimSpace(requirement))
+	if match == nil {
+		return "", ""
+	}
+	version := strings.Trim(strings.TrimSpace(match[3]), "()")
+	return normalizePythonName(match[1]), strings.TrimSpace(version)
+}
+
+// normalizePythonName normalizes a package name as described in PEP 503.
+func normalizePythonName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+type pyproject struct {
+	Project struct {
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]interface{} `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+func parsePyproject(content string) (map[string]string, error) {
+	var doc pyproject
+	err := toml.Unmarshal([]byte(content), &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	addRequirements := func(requirements []string) {
+		for _, requirement := range requirements {
+			name, version := parsePythonRequirement(requirement)
+			if name != "" {
+				versions[name] = version
+			}
+		}
+	}
+	addRequirements(doc.Project.Dependencies)
+	for _, requirements := range doc.Project.OptionalDependencies {
+		addRequirements(requirements)
+	}
+
+	addPoetry := func(deps map[string]interface{}) {
+		for name, spec := range deps {
+			if name == "python" {
+				continue
+			}
+			versions[normalizePythonName(name)] = poetryVersion(spec)
+		}
+	}
+	addPoetry(doc.Tool.Poetry.Dependencies)
+	addPoetry(doc.Tool.Poetry.DevDependencies)
+	for _, group := range doc.Tool.Poetry.Group {
+		addPoetry(group.Dependencies)
+	}
+	return versions, nil
+}
+
+// poetryVersion returns the version of a dependency that is either given
+// as plain string or as table with a version, git, path or url key.
+func poetryVersion(spec interface{}) string {
+	switch s := spec.(type) {
+	case string:
+		return s
+	case map[string]interface{}:
+		if version, ok := s["version"].(string); ok {
+			return version
+		}
+		if git, ok := s["git"].(string); ok {
+			for _, ref := range []string{"rev", "tag", "branch"} {
+				if value, ok := s[ref].(string); ok {
+					return "git " + git + "#" + value
+				}
+			}
+			return "git " + git
+		}
+		if p, ok := s["path"].(string); ok {
+			return "path " + p
+		}
+		if url, ok := s["url"].(string); ok {
+			return "url " + url
+		}
+	}
+	return ""
+}
+
+type poetryLock struct {
+	Packages []struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+	} `toml:"package"`
+}
+
+func parsePoetryLock(content string) (map[string]string, error) {
+	var lock poetryLock
+	err := toml.Unmarshal([]byte(content), &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	for _, pkg := range lock.Packages {
+		addVersion(versions, normalizePythonName(pkg.Name), pkg.Version)
+	}
+	return versions, nil
+}
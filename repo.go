@@ -2,6 +2,8 @@ package deckard
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
@@ -12,12 +14,29 @@ import (
 
 // RepoUpdate refreshes all repo based resources after the UI has been started.
 func UpdateFromRepo(ui *DeckardUI) {
+	ui.state.fetching = true
 	go backgroundUpdate(ui)
 }
 
 func backgroundUpdate(ui *DeckardUI) {
 	updateRepos(ui)
+	refitWeights(ui)
 	updateCommits(ui)
+
+	ui.app.QueueUpdateDraw(func() {
+		ui.state.fetching = false
+	})
+}
+
+// refitWeights refits the rule weights of the projects that are due, so that
+// the new commits are scored with them.
+func refitWeights(ui *DeckardUI) {
+	updateStatus(ui, "Refitting rule weights...")
+	_, err := RefitWeights(ui.config, ui.db, "", false)
+	if err != nil {
+		panic(err) //TODO show error in UI
+	}
+	clearStatus(ui)
 }
 
 func updateCommits(ui *DeckardUI) {
@@ -36,33 +55,33 @@ func updateCommits(ui *DeckardUI) {
 		}
 
 		folder := repoFolder(ui.config, conf)
-		log, err := logRepo(folder, since)
+		log, err := logRepo(folder, conf, since)
 		if err != nil {
 			panic(err)
 		}
 
+		history, err := loadProjectHistory(ui.db, ui.scorer, prj)
+		if err != nil {
+			panic(err) //TODO show error in UI
+		}
+
 		var lastCommitTime = since
 		repoCommits := make([]*Commit, 0)
 
 		for _, commit := range log {
-			diff, err := diffRepo(folder, commit.Hash)
-			if err != nil {
-				panic(fmt.Errorf("diff failed %s, %s, %w", folder, commit.Hash, err)) // TODO show error in UI
-			}
-
-			slatScore, err := slatScore(diff)
+			commit.Project = prj
+			err := analyzeCommit(ui.scorer, history, folder, commit)
 			if err != nil {
 				panic(err) // TODO show error in UI
 			}
-			commit.Project = prj
 			commit.State = STATE_NEW
-			commit.SlatScore = slatScore
 
 			// TODO go back to AuthorWhen???
 			if commit.CommitWhen.After(*lastCommitTime) {
 				lastCommitTime = &commit.CommitWhen
 			}
 
+			history.add(commit)
 			repoCommits = append(repoCommits, commit)
 		}
 
@@ -86,6 +105,93 @@ func updateCommits(ui *DeckardUI) {
 	clearStatus(ui)
 }
 
+// analyzeCommit gathers the diff of the commit from the clone in folder, scores
+// it against the project history and sets the results on the commit.
+func analyzeCommit(scorer *slatScorer, history *projectHistory, folder string, commit *Commit) error {
+	diff, err := diffRepo(folder, commit.Hash)
+	if err != nil {
+		return fmt.Errorf("diff failed %s, %s, %w", folder, commit.Hash, err)
+	}
+
+	var manifestFailures string
+	diff.Dependencies, manifestFailures, err = dependencyChanges(folder, commit.Hash, diff)
+	if err != nil {
+		return fmt.Errorf("dependency analysis failed %s, %s, %w", folder, commit.Hash, err)
+	}
+
+	var advisoryFailures string
+	diff.Vulnerabilities, advisoryFailures, err = scorer.vulnerabilities.match(diff.Dependencies)
+	if err != nil {
+		return fmt.Errorf("advisory matching failed %s, %s, %w", folder, commit.Hash, err)
+	}
+
+	diff.Typosquats, err = typosquats(scorer.typosquats, folder, commit.Hash, diff.Dependencies)
+	if err != nil {
+		return fmt.Errorf("typosquat check failed %s, %s, %w", folder, commit.Hash, err)
+	}
+
+	diff.Binaries, err = binaryFiles(folder, commit.Hash, diff)
+	if err != nil {
+		return fmt.Errorf("binary analysis failed %s, %s, %w", folder, commit.Hash, err)
+	}
+
+	diff.Classes, err = fileClasses(folder, commit.Hash, diff)
+	if err != nil {
+		return fmt.Errorf("file classification failed %s, %s, %w", folder, commit.Hash, err)
+	}
+
+	diff.APIChanges, err = goAPIChanges(folder, commit.Hash, diff)
+	if err != nil {
+		return fmt.Errorf("API analysis failed %s, %s, %w", folder, commit.Hash, err)
+	}
+
+	diff.Licenses, err = licenseChanges(scorer.licenses, scorer.projectLicenses[commit.Project], folder, commit.Hash, diff)
+	if err != nil {
+		return fmt.Errorf("license analysis failed %s, %s, %w", folder, commit.Hash, err)
+	}
+
+	owners, err := loadCodeOwners(folder, commit.Hash)
+	if err != nil {
+		return fmt.Errorf("CODEOWNERS failed %s, %s, %w", folder, commit.Hash, err)
+	}
+	diff.Owners = fileOwners(owners, diff)
+
+	var analyzerFailures string
+	diff.Findings, analyzerFailures, err = runAnalyzers(scorer.analyzers, folder, commit.Hash, diff)
+	if err != nil {
+		return fmt.Errorf("analyzers failed %s, %s, %w", folder, commit.Hash, err)
+	}
+
+	diff.External, commit.ScoringFailed = runScorers(scorer.external, commit, diff)
+	for _, failures := range []string{manifestFailures, advisoryFailures, analyzerFailures} {
+		if failures != "" {
+			commit.ScoringFailed = strings.TrimPrefix(commit.ScoringFailed+"; "+failures, "; ")
+		}
+	}
+
+	slatScore, reasons, err := scorer.slatScore(history, commit, diff)
+	if err != nil {
+		return err
+	}
+	commit.SlatScore = slatScore
+	commit.Reasons = reasons
+	commit.Contributor, _ = contributorReasons(history.contributors, scorer.contributors, commit)
+	commit.Tests, _ = testReasons(scorer.tests, diff)
+	commit.Dependencies = diff.Dependencies
+	commit.Vulnerabilities = diff.Vulnerabilities
+	commit.APIChanges = diff.APIChanges
+	commit.Licenses = diff.Licenses
+	commit.Findings = diff.Findings
+	commit.Owners = allOwners(diff.Owners)
+	sample := diffChurn(diff)
+	commit.LinesChanged = sample.Lines
+	commit.FilesChanged = sample.Files
+	byClass := classChurn(diff)
+	commit.GeneratedLines = byClass[FILE_GENERATED]
+	commit.VendoredLines = byClass[FILE_VENDORED]
+	return nil
+}
+
 // check if repos are there, if not clones them. If they exist
 // they are pulled to the latest state.
 func updateRepos(ui *DeckardUI) {
@@ -124,36 +230,74 @@ func pullRepo(targetFolder string) error {
 	return cmd.Run()
 }
 
-func logRepo(targetFolder string, since *time.Time) ([]*Commit, error) {
-	sinceArg := fmt.Sprintf("--since=%s", since.Format(time.RFC3339))
-	cmd := exec.Command("git", "log", sinceArg, "--format=%H%x00%an%x00%cn%x00%ct%x00%s%x00%b%x00")
-	cmd.Dir = targetFolder
-	out, err := cmd.Output()
+func logRepo(targetFolder string, prjConf ConfigProject, since *time.Time) ([]*Commit, error) {
+	out, err := gitLog(targetFolder, prjConf, fmt.Sprintf("--since=%s", since.Format(time.RFC3339)))
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(targetFolder, out)
+}
+
+// showCommit reads a single commit from the clone, nil if the clone does not
+// contain it (anymore).
+func showCommit(targetFolder string, prjConf ConfigProject, hash string) (*Commit, error) {
+	out, err := gitLog(targetFolder, prjConf, "-1", hash)
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 128 {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
+	commits, err := parseLog(targetFolder, out)
+	if err != nil || len(commits) == 0 {
+		return nil, err
+	}
+	return commits[0], nil
+}
 
+func gitLog(targetFolder string, prjConf ConfigProject, logArgs ...string) ([]byte, error) {
+	args := make([]string, 0)
+	if prjConf.AllowedSigners != "" {
+		args = append(args, "-c", "gpg.ssh.allowedSignersFile="+prjConf.AllowedSigners)
+	}
+	args = append(args, "log", "--format=%H%x00%an%x00%ae%x00%cn%x00%ce%x00%ct%x00%G?%x00%GK%x00%s%x00%b%x00")
+	args = append(args, logArgs...)
+	args = append(args, "--")
+	cmd := exec.Command("git", args...)
+	cmd.Dir = targetFolder
+	if prjConf.GPGHome != "" {
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+prjConf.GPGHome)
+	}
+	return cmd.Output()
+}
+
+func parseLog(targetFolder string, out []byte) ([]*Commit, error) {
 	split := strings.Split(string(out), "\x00")
 
 	commits := make([]*Commit, 0)
-	for i := 0; i < len(split); i += 6 {
+	for i := 0; i < len(split); i += 10 {
 
-		if i+6 > len(split) {
+		if i+10 > len(split) {
 			break
 		}
 
-		cwUnix, err := strconv.Atoi(split[i+3])
+		cwUnix, err := strconv.Atoi(split[i+5])
 		if err != nil {
-			return nil, fmt.Errorf("illegal commit time: %s, folder = %s", split[i+3], targetFolder)
+			return nil, fmt.Errorf("illegal commit time: %s, folder = %s", split[i+5], targetFolder)
 		}
 
 		commits = append(commits, &Commit{
-			Hash:          strings.TrimSpace(split[i]),
-			AuthorName:    split[i+1],
-			CommitterName: split[i+2],
-			CommitWhen:    time.Unix(int64(cwUnix), 0),
-			Subject:       split[i+4],
-			Message:       split[i+5],
+			Hash:           strings.TrimSpace(split[i]),
+			AuthorName:     split[i+1],
+			AuthorEmail:    split[i+2],
+			CommitterName:  split[i+3],
+			CommitterEmail: split[i+4],
+			CommitWhen:     time.Unix(int64(cwUnix), 0),
+			Signature:      split[i+6],
+			SigningKey:     split[i+7],
+			Subject:        split[i+8],
+			Message:        split[i+9],
+			References:     extractReferences(split[i+8] + "\n" + split[i+9]),
 		})
 	}
 	return commits, nil
@@ -163,31 +307,239 @@ type NumStat struct {
 	Added   uint64
 	Deleted uint64
 	File    string
+	Binary  bool // Added and Deleted are always 0 for binary files
+}
+
+// FilePatch contains the lines a commit added to and removed from a file.
+type FilePatch struct {
+	File    string
+	Added   []PatchLine
+	Removed []PatchLine
+}
+
+type PatchLine struct {
+	Num  int // line number in the new file, in the old one for removed lines
+	Text string
 }
 
 type Diff struct {
-	Stats []NumStat
+	Stats           []NumStat
+	Patch           []FilePatch
+	Dependencies    []DependencyChange
+	Owners          map[string][]string // file -> code owners, only files that have owners
+	Binaries        []BinaryFile
+	APIChanges      []APIChange
+	TreeChanges     []TreeChange
+	Classes         map[string]string // numstat file -> generated or vendored, only files that are not handwritten
+	External        []Reason          // reasons of the external scorers
+	Vulnerabilities []VulnerabilityMatch
+	Licenses        []LicenseChange
+	Typosquats      []Typosquat
+	Findings        []AnalyzerFinding // new findings of the local analyzers
 }
 
 func diffRepo(targetFolder, hash string) (*Diff, error) {
-	cmd := exec.Command("git", "diff", "--numstat", fmt.Sprintf("%s..%s^", hash, hash), "--")
-	cmd.Dir = targetFolder
-	diffOut, err := cmd.Output()
+	diffOut, err := gitDiff(targetFolder, hash, "--numstat")
 	if err != nil {
-		errExit := err.(*exec.ExitError)
-		if errExit.ExitCode() == 128 { //probably the first commit, return an empty commit
-			return &Diff{}, nil
-		}
-		return nil, fmt.Errorf("diff command failed: %w, out=%s", err, string(errExit.Stderr))
+		return nil, err
+	}
+	if diffOut == nil { // probably the first commit, return an empty commit
+		return &Diff{}, nil
 	}
 
 	parsed, err := parseNumStat(string(diffOut))
 	if err != nil {
 		return nil, err
 	}
+
+	patchOut, err := gitDiff(targetFolder, hash, "--unified=0", "--no-color", "--no-ext-diff")
+	if err != nil {
+		return nil, err
+	}
+	parsed.Patch, err = parsePatch(string(patchOut))
+	if err != nil {
+		return nil, err
+	}
+
+	parsed.TreeChanges, err = treeChanges(targetFolder, hash)
+	if err != nil {
+		return nil, err
+	}
 	return parsed, nil
 }
 
+// gitDiff diffs the commit against its parent. It returns nil output if the
+// commit has no parent.
+func gitDiff(targetFolder, hash string, args ...string) ([]byte, error) {
+	cmdArgs := append([]string{"-c", "core.quotePath=false", "diff"}, args...)
+	cmdArgs = append(cmdArgs, hash+"^", hash, "--")
+	cmd := exec.Command("git", cmdArgs...)
+	cmd.Dir = targetFolder
+	out, err := cmd.Output()
+	if err != nil {
+		errExit, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, fmt.Errorf("diff command failed: %w", err)
+		}
+		if errExit.ExitCode() == 128 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("diff command failed: %w, out=%s", err, string(errExit.Stderr))
+	}
+	return out, nil
+}
+
+// parsePatch collects the added and removed lines per file from a unified
+// diff. Removed lines of deleted files are skipped.
+func parsePatch(raw string) ([]FilePatch, error) {
+	patches := make([]FilePatch, 0)
+	current := -1 // index of the current file in patches, -1 if lines are skipped
+	inHeader := false
+	lineNum := 0
+	oldLineNum := 0
+	for _, line := range strings.Split(raw, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current = -1
+			inHeader = true
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			file := strings.TrimPrefix(line, "+++ ")
+			if file == "/dev/null" { // deleted file
+				continue
+			}
+			patches = append(patches, FilePatch{File: strings.TrimPrefix(file, "b/")})
+			current = len(patches) - 1
+		case strings.HasPrefix(line, "@@ "):
+			inHeader = false
+			// @@ -l,s +l,s @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+				return nil, fmt.Errorf("unexpected hunk header: %s", line)
+			}
+			oldNum, err := strconv.Atoi(strings.Split(strings.TrimPrefix(fields[1], "-"), ",")[0])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected hunk header: %s", line)
+			}
+			num, err := strconv.Atoi(strings.Split(strings.TrimPrefix(fields[2], "+"), ",")[0])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected hunk header: %s", line)
+			}
+			lineNum, oldLineNum = num, oldNum
+		case inHeader || current < 0:
+			continue
+		case strings.HasPrefix(line, "+"):
+			patches[current].Added = append(patches[current].Added, PatchLine{Num: lineNum, Text: line[1:]})
+			lineNum++
+		case strings.HasPrefix(line, "-"):
+			patches[current].Removed = append(patches[current].Removed, PatchLine{Num: oldLineNum, Text: line[1:]})
+			oldLineNum++
+		case strings.HasPrefix(line, " "):
+			lineNum++
+			oldLineNum++
+		}
+	}
+	return patches, nil
+}
+
+// showFile returns the content of file at revision rev. found is false if
+// the file does not exist at this revision.
+func showFile(targetFolder, rev, file string) (content string, found bool, err error) {
+	cmd := exec.Command("git", "show", fmt.Sprintf("%s:%s", rev, file))
+	cmd.Dir = targetFolder
+	out, err := cmd.Output()
+	if err != nil {
+		errExit, ok := err.(*exec.ExitError)
+		if ok && errExit.ExitCode() == 128 { // file (or revision) does not exist
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("show command failed: %w", err)
+	}
+	return string(out), true, nil
+}
+
+// headFile returns the first n bytes of file at revision rev. found is false
+// if the file does not exist at this revision.
+func headFile(targetFolder, rev, file string, n int) (head []byte, found bool, err error) {
+	cmd := exec.Command("git", "cat-file", "blob", fmt.Sprintf("%s:%s", rev, file))
+	cmd.Dir = targetFolder
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, false, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, false, err
+	}
+
+	head = make([]byte, n)
+	read, _ := io.ReadFull(stdout, head)
+	io.Copy(ioutil.Discard, stdout) // the rest is not needed, but git must be able to finish
+	err = cmd.Wait()
+	if err != nil {
+		errExit, ok := err.(*exec.ExitError)
+		if ok && errExit.ExitCode() == 128 { // file (or revision) does not exist
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("cat-file command failed: %w", err)
+	}
+	return head[:read], true, nil
+}
+
+// newFileName returns the file name after the commit for numstat renames
+// like "a/{b => c}/d" or "a => b".
+func newFileName(file string) string {
+	_, newName := renamedFileNames(file)
+	return newName
+}
+
+// oldFileName returns the file name before the commit for numstat renames.
+func oldFileName(file string) string {
+	oldName, _ := renamedFileNames(file)
+	return oldName
+}
+
+func renamedFileNames(file string) (oldName, newName string) {
+	arrow := strings.Index(file, " => ")
+	if arrow < 0 {
+		return file, file
+	}
+	open := strings.LastIndex(file[:arrow], "{")
+	close := strings.Index(file[arrow:], "}")
+	if open < 0 || close < 0 {
+		return file[:arrow], file[arrow+len(" => "):]
+	}
+	close += arrow
+	oldName = path.Clean(file[:open] + file[open+1:arrow] + file[close+1:])
+	newName = path.Clean(file[:open] + file[arrow+len(" => "):close] + file[close+1:])
+	return oldName, newName
+}
+
+// listFiles returns the files directly in folder dir at revision rev, nil if
+// the revision does not exist.
+func listFiles(targetFolder, rev, dir string) ([]string, error) {
+	args := []string{"ls-tree", "--name-only", "-z", rev}
+	if dir != "." {
+		args = append(args, "--", dir+"/")
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = targetFolder
+	out, err := cmd.Output()
+	if err != nil {
+		errExit, ok := err.(*exec.ExitError)
+		if ok && errExit.ExitCode() == 128 { // revision does not exist
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ls-tree command failed: %w", err)
+	}
+	files := make([]string, 0)
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
 func parseNumStat(raw string) (*Diff, error) {
 
 	if len(raw) == 0 {
@@ -207,19 +559,6 @@ func parseNumStat(raw string) (*Diff, error) {
 			return nil, fmt.Errorf("unexpected diff line: %s", line)
 		}
 
-		added, err := strconv.ParseUint(fields[0], 10, 32)
-		if err != nil {
-			//TODO At least log an error here
-			stats = append(stats, NumStat{Added: 0, Deleted: 0, File: ""})
-			continue
-		}
-		deleted, err := strconv.ParseUint(fields[1], 10, 32)
-		if err != nil {
-			//TODO At least log an error here
-			stats = append(stats, NumStat{Added: 0, Deleted: 0, File: ""})
-			continue
-		}
-
 		file := ""
 		for i := 2; i < len(fields); i++ {
 			if i != 2 {
@@ -228,6 +567,20 @@ func parseNumStat(raw string) (*Diff, error) {
 			file += fields[i]
 		}
 
+		if fields[0] == "-" && fields[1] == "-" { // git does not count lines of binary files
+			stats = append(stats, NumStat{File: file, Binary: true})
+			continue
+		}
+
+		added, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected added count in diff line: %s", line)
+		}
+		deleted, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected deleted count in diff line: %s", line)
+		}
+
 		stats = append(stats, NumStat{Added: added, Deleted: deleted, File: file})
 	}
 
@@ -1,11 +1,47 @@
 package deckard
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 )
 
+// newTestRepo creates a git repository in a temporary folder, the test is
+// skipped if git is not installed.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	folder := t.TempDir()
+	if err := git(folder, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+	return folder
+}
+
+// commitFiles writes the files (path -> content) to the repository and
+// commits them.
+func commitFiles(t *testing.T, folder string, files map[string]string) {
+	t.Helper()
+	for file, content := range files {
+		if err := os.MkdirAll(filepath.Join(folder, filepath.Dir(file)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(folder, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"add", "."}, {"-c", "user.name=a", "-c", "user.email=a@b", "commit", "-q", "-m", "c"}} {
+		if err := git(folder, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 func TestDiffRepo(t *testing.T) {
 	tc := []struct {
 		desc         string
@@ -21,21 +57,28 @@ func TestDiffRepo(t *testing.T) {
 			desc:    "small diff",
 			diffStr: "14      0       repo.go\n1       16      slat.go",
 			expectedDiff: &Diff{
-				[]NumStat{{14, 0, "repo.go"}, {1, 16, "slat.go"}},
+				Stats: []NumStat{{Added: 14, Deleted: 0, File: "repo.go"}, {Added: 1, Deleted: 16, File: "slat.go"}},
 			},
 		},
 		{
 			desc:    "diff with extra newline at the end",
 			diffStr: "14      0       repo.go\n1       16      slat.go\n",
 			expectedDiff: &Diff{
-				[]NumStat{{14, 0, "repo.go"}, {1, 16, "slat.go"}},
+				Stats: []NumStat{{Added: 14, Deleted: 0, File: "repo.go"}, {Added: 1, Deleted: 16, File: "slat.go"}},
+			},
+		},
+		{
+			desc:    "binary file",
+			diffStr: "-       -       tests/files/bad-3-corrupt_lzma2.xz\n1       16      slat.go",
+			expectedDiff: &Diff{
+				Stats: []NumStat{{File: "tests/files/bad-3-corrupt_lzma2.xz", Binary: true}, {Added: 1, Deleted: 16, File: "slat.go"}},
 			},
 		},
 		{
 			desc:    "move commit",
 			diffStr: "0      0       services/{foo => echo}/Makefile\n1       16      slat.go",
 			expectedDiff: &Diff{
-				[]NumStat{{0, 0, "services/{foo => echo}/Makefile"}, {1, 16, "slat.go"}},
+				Stats: []NumStat{{Added: 0, Deleted: 0, File: "services/{foo => echo}/Makefile"}, {Added: 1, Deleted: 16, File: "slat.go"}},
 			},
 		},
 	}
@@ -51,3 +94,68 @@ func TestDiffRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePatch(t *testing.T) {
+	raw := `diff --git a/repo.go b/repo.go
+index 3b18e51..a4c2d9b 100644
+--- a/repo.go
++++ b/repo.go
+@@ -10,0 +11,2 @@ func foo() {
++	bar()
+++++ not a header
+@@ -20 +22 @@ func baz() {
+-	old()
++	new()
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+index 3b18e51..0000000
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package gone
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3b18e51
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1 @@
++package new
+`
+	expected := []FilePatch{
+		{File: "repo.go", Added: []PatchLine{{11, "\tbar()"}, {12, "+++ not a header"}, {22, "\tnew()"}}, Removed: []PatchLine{{20, "\told()"}}},
+		{File: "new.go", Added: []PatchLine{{1, "package new"}}},
+	}
+
+	patches, err := parsePatch(raw)
+	if err != nil {
+		t.Errorf("unexpected error: %#v", err)
+	}
+	if diff := cmp.Diff(patches, expected); diff != "" {
+		t.Errorf("unexpected patch: %s", diff)
+	}
+}
+
+func TestRenamedFileNames(t *testing.T) {
+	tc := []struct {
+		file        string
+		expectedOld string
+		expectedNew string
+	}{
+		{file: "main.go", expectedOld: "main.go", expectedNew: "main.go"},
+		{file: "old.go => new.go", expectedOld: "old.go", expectedNew: "new.go"},
+		{file: "pkg/{a => b}/main.go", expectedOld: "pkg/a/main.go", expectedNew: "pkg/b/main.go"},
+		{file: "pkg/{ => sub}/main.go", expectedOld: "pkg/main.go", expectedNew: "pkg/sub/main.go"},
+		{file: "pkg/{sub => }/main.go", expectedOld: "pkg/sub/main.go", expectedNew: "pkg/main.go"},
+	}
+
+	for _, c := range tc {
+		t.Run(c.file, func(t *testing.T) {
+			if file := oldFileName(c.file); file != c.expectedOld {
+				t.Errorf("expected old '%s', got '%s'", c.expectedOld, file)
+			}
+			if file := newFileName(c.file); file != c.expectedNew {
+				t.Errorf("expected new '%s', got '%s'", c.expectedNew, file)
+			}
+		})
+	}
+}
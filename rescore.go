@@ -0,0 +1,93 @@
+package deckard
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// RescoreProgress is called after each rescored commit of a project.
+type RescoreProgress func(project string, done, total int)
+
+// Rescore recomputes the slat score and the stored analysis of all stored
+// commits of the project, or of all projects if project is empty. Use it
+// after the scoring config changed.
+func Rescore(config *Config, db *sql.DB, project string, progress RescoreProgress) error {
+	scorer, err := newSlatScorer(config)
+	if err != nil {
+		return err
+	}
+	return rescore(scorer, config, db, project, progress)
+}
+
+func backgroundRescore(ui *DeckardUI, project string) {
+	err := rescore(ui.scorer, ui.config, ui.db, project, func(project string, done, total int) {
+		updateStatus(ui, fmt.Sprintf("Rescoring %s: %d/%d", project, done, total))
+	})
+	if err != nil {
+		panic(err) //TODO show error in UI
+	}
+
+	ui.app.QueueUpdateDraw(func() {
+		ui.state.rescoring = false
+		ui.state.commits = make([]*Commit, 0) // replace the commits with the rescored ones
+		UpdateFromDB(ui.db, ui)
+	})
+
+	clearStatus(ui)
+}
+
+// rescore re-reads the stored commits from the local clones and scores them
+// again. Commits that are no longer in the clone are skipped. The history is
+// the one a fetch would see now, so a commit is compared with all stored
+// commits of its project, not only with the older ones.
+func rescore(scorer *slatScorer, config *Config, db *sql.DB, project string, progress RescoreProgress) error {
+	projects := make([]string, 0, len(config.Projects))
+	if project != "" {
+		if _, ok := config.Projects[project]; !ok {
+			return fmt.Errorf("unknown project '%s'", project)
+		}
+		projects = append(projects, project)
+	} else {
+		for prj := range config.Projects {
+			projects = append(projects, prj)
+		}
+		sort.Strings(projects)
+	}
+
+	for _, prj := range projects {
+		conf := config.Projects[prj]
+		folder := repoFolder(config, conf)
+
+		hashes, err := loadCommitHashes(db, prj)
+		if err != nil {
+			return err
+		}
+		history, err := loadProjectHistory(db, scorer, prj)
+		if err != nil {
+			return err
+		}
+
+		for i, hash := range hashes {
+			commit, err := showCommit(folder, conf, hash)
+			if err != nil {
+				return fmt.Errorf("reading commit failed %s, %s, %w", folder, hash, err)
+			}
+			if commit != nil {
+				commit.Project = prj
+				err = analyzeCommit(scorer, history, folder, commit)
+				if err != nil {
+					return err
+				}
+				err = updateAnalysis(db, commit)
+				if err != nil {
+					return err
+				}
+			}
+			if progress != nil {
+				progress(prj, i+1, len(hashes))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package deckard
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type secretPattern struct {
+	kind string
+	re   *regexp.Regexp
+	// index of the sub match that contains the secret, 0 for the whole match
+	group int
+	// minimal shannon entropy of the secret, 0 disables the check
+	minEntropy float64
+}
+
+var secretPatterns = []secretPattern{
+	{kind: "aws access key", re: regexp.MustCompile(`\b((?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[0-9A-Z]{16})\b`), group: 1},
+	{kind: "aws secret key", re: regexp.MustCompile(`(?i)aws.{0,20}(?:secret|key).{0,10}[=:]\s*["'` + "`" + `]?([A-Za-z0-9/+=]{40})\b`), group: 1, minEntropy: 3.5},
+	{kind: "private key", re: regexp.MustCompile(`-----BEGIN ((RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)},
+	{kind: "github token", re: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,})\b`), group: 1},
+	{kind: "slack token", re: regexp.MustCompile(`\b(xox[baprs]-[A-Za-z0-9-]{10,})\b`), group: 1},
+	{kind: "google api key", re: regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})\b`), group: 1},
+	{kind: "url credentials", re: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@"']+:([^/\s:@"']+)@[^\s"']+`), group: 1},
+	{kind: "high entropy token", re: regexp.MustCompile(`(?i)(?:api[_-]?key|token|secret|passw(?:or)?d|pwd|credentials?|auth)["']?\s*[:=]+\s*["'` + "`" + `]([A-Za-z0-9+/=_.\-]{20,})["'` + "`" + `]`), group: 1, minEntropy: 4.0},
+}
+
+// rules of the reasons for leaked secrets start with this, such a reason
+// always scores the maximum and is not adjusted by weights or factors
+const secretRulePrefix = "secret "
+
+func isSecretReason(reason Reason) bool {
+	return strings.HasPrefix(reason.Rule, secretRulePrefix)
+}
+
+// files that naturally contain hashes which look like high entropy tokens
+var secretEntropyIgnoredFiles = []string{"go.sum", "Cargo.lock", "package-lock.json", "yarn.lock", "pubspec.lock", "poetry.lock"}
+
+// scanSecrets scans the added lines of a patch for credentials. The
+// returned findings are redacted.
+func scanSecrets(patches []FilePatch) []Finding {
+	findings := make([]Finding, 0)
+	for _, patch := range patches {
+		ignoreEntropy := isSecretEntropyIgnored(patch.File)
+		for _, line := range patch.Added {
+			for _, pattern := range secretPatterns {
+				if pattern.minEntropy > 0 && ignoreEntropy {
+					continue
+				}
+				match := pattern.re.FindStringSubmatchIndex(line.Text)
+				if match == nil {
+					continue
+				}
+				start, end := match[2*pattern.group], match[2*pattern.group+1]
+				secret := line.Text[start:end]
+				if pattern.minEntropy > 0 && shannonEntropy(secret) < pattern.minEntropy {
+					continue
+				}
+				text := secret
+				if pattern.group != 0 {
+					// the secret text may also occur earlier in the match, e.g. as user name
+					text = line.Text[match[0]:start] + redact(secret) + line.Text[end:match[1]]
+				}
+				findings = append(findings, Finding{File: patch.File, Line: line.Num, Kind: pattern.kind, Text: text})
+				break // one finding per line is enough
+			}
+		}
+	}
+	return findings
+}
+
+func isSecretEntropyIgnored(file string) bool {
+	base := path.Base(file)
+	for _, ignored := range secretEntropyIgnoredFiles {
+		if base == ignored {
+			return true
+		}
+	}
+	return false
+}
+
+// redact keeps the first four characters of a secret only.
+func redact(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", len(secret)-4)
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	entropy := 0.0
+	length := float64(len([]rune(s)))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,91 @@
+package deckard
+
+import (
+	"fmt"
+)
+
+// signature states as reported by git's %G? placeholder
+const (
+	SIGNATURE_GOOD             = "G"
+	SIGNATURE_BAD              = "B"
+	SIGNATURE_UNKNOWN_VALIDITY = "U"
+	SIGNATURE_EXPIRED          = "X"
+	SIGNATURE_EXPIRED_KEY      = "Y"
+	SIGNATURE_REVOKED_KEY      = "R"
+	SIGNATURE_MISSING_KEY      = "E"
+	SIGNATURE_NONE             = "N"
+)
+
+// signatureHistory counts how many of a project's known commits are signed.
+type signatureHistory struct {
+	signed int
+	total  int
+}
+
+func (h *signatureHistory) add(status string) {
+	if status == "" { // stored before signatures were recorded
+		return
+	}
+	h.total++
+	if status != SIGNATURE_NONE {
+		h.signed++
+	}
+}
+
+// normallySigns reports whether the project signs its commits.
+func (h *signatureHistory) normallySigns(conf ConfigSignatures) bool {
+	if h == nil || h.total == 0 || h.total < conf.MinHistory {
+		return false
+	}
+	return float64(h.signed)/float64(h.total) >= conf.MinRatio
+}
+
+func signatureDescription(status string) string {
+	switch status {
+	case SIGNATURE_GOOD:
+		return "good signature"
+	case SIGNATURE_BAD:
+		return "bad signature"
+	case SIGNATURE_UNKNOWN_VALIDITY:
+		return "good signature, unknown validity"
+	case SIGNATURE_EXPIRED:
+		return "expired signature"
+	case SIGNATURE_EXPIRED_KEY:
+		return "signature by expired key"
+	case SIGNATURE_REVOKED_KEY:
+		return "signature by revoked key"
+	case SIGNATURE_MISSING_KEY:
+		return "signature by unknown key"
+	case SIGNATURE_NONE:
+		return "unsigned"
+	}
+	return "signature not checked"
+}
+
+// signatureReason scores the signature of the commit. A bad signature is
+// always scored, unsigned commits and unknown keys only if the project
+// normally signs its commits.
+func signatureReason(conf ConfigSignatures, history *signatureHistory, commit *Commit) (Reason, bool) {
+	weight := 0
+	switch commit.Signature {
+	case SIGNATURE_BAD, SIGNATURE_REVOKED_KEY:
+		weight = conf.BadWeight
+	case SIGNATURE_MISSING_KEY, SIGNATURE_UNKNOWN_VALIDITY, SIGNATURE_EXPIRED, SIGNATURE_EXPIRED_KEY:
+		if history.normallySigns(conf) {
+			weight = conf.UnknownWeight
+		}
+	case SIGNATURE_NONE:
+		if history.normallySigns(conf) {
+			weight = conf.UnsignedWeight
+		}
+	}
+	if weight == 0 {
+		return Reason{}, false
+	}
+
+	match := signatureDescription(commit.Signature)
+	if commit.SigningKey != "" {
+		match = fmt.Sprintf("%s (key %s)", match, commit.SigningKey)
+	}
+	return Reason{Rule: "signature", Match: match, Contribution: weight}, true
+}
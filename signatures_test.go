@@ -0,0 +1,38 @@
+package deckard
+
+import (
+	"testing"
+)
+
+func TestSignatureReason(t *testing.T) {
+	conf := ConfigSignatures{BadWeight: 100, UnknownWeight: 40, UnsignedWeight: 50, MinRatio: 0.8, MinHistory: 10}
+	signing := &signatureHistory{signed: 19, total: 20}
+	notSigning := &signatureHistory{signed: 2, total: 20}
+	short := &signatureHistory{signed: 5, total: 5}
+
+	tc := []struct {
+		desc     string
+		history  *signatureHistory
+		status   string
+		expected int
+	}{
+		{desc: "good signature", history: signing, status: SIGNATURE_GOOD, expected: 0},
+		{desc: "bad signature", history: notSigning, status: SIGNATURE_BAD, expected: 100},
+		{desc: "revoked key", history: signing, status: SIGNATURE_REVOKED_KEY, expected: 100},
+		{desc: "unknown key in signing project", history: signing, status: SIGNATURE_MISSING_KEY, expected: 40},
+		{desc: "unknown key in not signing project", history: notSigning, status: SIGNATURE_MISSING_KEY, expected: 0},
+		{desc: "unsigned in signing project", history: signing, status: SIGNATURE_NONE, expected: 50},
+		{desc: "unsigned in not signing project", history: notSigning, status: SIGNATURE_NONE, expected: 0},
+		{desc: "unsigned with short history", history: short, status: SIGNATURE_NONE, expected: 0},
+		{desc: "not checked", history: signing, status: "", expected: 0},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			reason, _ := signatureReason(conf, c.history, &Commit{Signature: c.status})
+			if reason.Contribution != c.expected {
+				t.Errorf("expected contribution %d, got %d", c.expected, reason.Contribution)
+			}
+		})
+	}
+}
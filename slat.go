@@ -1,23 +1,493 @@
 package deckard
 
 import (
+	"database/sql"
+	"fmt"
+	"path"
+	"regexp"
 	"strings"
 )
 
+const maxSlatScore = 100
+
+type slatScorer struct {
+	rules             []*rule
+	external          []*externalScorer
+	analyzers         []*localAnalyzer
+	profiles          map[string]*scoringProfile // by project, projects without one use defaultProfile
+	patterns          []*codePattern
+	keywords          []*messageKeyword
+	dependencyWeights map[string]int
+	binaryWeights     map[string]int
+	buildWeights      map[string]int
+	apiWeights        map[string]int
+	treeWeights       map[string]int
+	licenseWeights    map[string]int
+	churn             ConfigChurn
+	contributors      ConfigContributors
+	codeOwners        ConfigCodeOwners
+	signatures        ConfigSignatures
+	messages          ConfigMessages
+	tests             ConfigTests
+	advisories        ConfigAdvisories
+	licenses          ConfigLicenses
+	typosquats        ConfigTyposquats
+	projectLicenses   map[string]string // declared license per project
+	vulnerabilities   *vulnerabilityDB  // nil if no advisory dump is configured
+}
+
+// projectHistory is what is known about a project from its stored commits.
+type projectHistory struct {
+	churn        *churnBaseline // nil if there is not enough history
+	contributors *contributorHistory
+	signatures   *signatureHistory
+	factors      map[string]float64 // fitted weight factor per rule
+}
+
+// add records a scored commit, so that later commits of the same update see it.
+func (h *projectHistory) add(commit *Commit) {
+	if h.contributors != nil {
+		h.contributors.add(commit.CommitWhen, commit.AuthorName, commit.CommitterName)
+	}
+	if h.signatures != nil {
+		h.signatures.add(commit.Signature)
+	}
+}
+
+type rule struct {
+	name     string
+	weight   int
+	paths    []*regexp.Regexp
+	messages []*regexp.Regexp
+	authors  []*regexp.Regexp
+	minChurn uint64
+	files    string // file class paths and minChurn are restricted to, empty for all files
+}
+
+func loadProjectHistory(db *sql.DB, scorer *slatScorer, project string) (*projectHistory, error) {
+	samples, err := loadChurnSamples(db, project, churnHistorySize)
+	if err != nil {
+		return nil, err
+	}
+	contributors, err := loadContributorHistory(db, project)
+	if err != nil {
+		return nil, err
+	}
+	signatures, err := loadSignatureHistory(db, project)
+	if err != nil {
+		return nil, err
+	}
+	factors, err := loadWeightFactors(db, project)
+	if err != nil {
+		return nil, err
+	}
+	return &projectHistory{
+		churn:        newChurnBaseline(samples, scorer.churn.MinHistory),
+		contributors: contributors,
+		signatures:   signatures,
+		factors:      factors,
+	}, nil
+}
+
+func newSlatScorer(config *Config) (*slatScorer, error) {
+	rules := make([]*rule, 0, len(config.Rules))
+	for _, ruleConfig := range config.Rules {
+		r, err := compileRule(ruleConfig)
+		if err != nil {
+			return nil, fmt.Errorf("rule '%s': %w", ruleConfig.Name, err)
+		}
+		rules = append(rules, r)
+	}
+
+	external := make([]*externalScorer, 0, len(config.Scorers))
+	for _, scorerConfig := range config.Scorers {
+		s, err := compileScorer(scorerConfig)
+		if err != nil {
+			return nil, err
+		}
+		external = append(external, s)
+	}
+
+	analyzers := make([]*localAnalyzer, 0, len(config.Analyzers))
+	for _, analyzerConfig := range config.Analyzers {
+		a, err := compileAnalyzer(analyzerConfig)
+		if err != nil {
+			return nil, err
+		}
+		analyzers = append(analyzers, a)
+	}
+
+	profiles, err := compileProfiles(config)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencyWeights, err := mergeWeights("dependency", defaultDependencyWeights, config.DependencyWeights)
+	if err != nil {
+		return nil, err
+	}
+
+	binaryWeights, err := mergeWeights("binary", defaultBinaryWeights, config.BinaryWeights)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns, err := compileCodePatterns(config.Patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	buildWeights, err := mergeWeights("build", defaultBuildWeights, config.BuildWeights)
+	if err != nil {
+		return nil, err
+	}
+
+	apiWeights, err := mergeWeights("api", defaultAPIWeights, config.APIWeights)
+	if err != nil {
+		return nil, err
+	}
+
+	treeWeights, err := mergeWeights("tree", defaultTreeWeights, config.TreeWeights)
+	if err != nil {
+		return nil, err
+	}
+
+	licenseWeights, err := mergeWeights("license", defaultLicenseWeights, config.LicenseWeights)
+	if err != nil {
+		return nil, err
+	}
+
+	projectLicenses := make(map[string]string)
+	for prj, conf := range config.Projects {
+		if conf.License != "" {
+			projectLicenses[prj] = conf.License
+		}
+	}
+
+	keywords, err := compileMessageKeywords(config.Messages.Keywords)
+	if err != nil {
+		return nil, err
+	}
+
+	for ecosystem := range config.Typosquats.Popular {
+		if _, ok := defaultPopularPackages[ecosystem]; !ok {
+			return nil, fmt.Errorf("typosquats: unknown ecosystem '%s'", ecosystem)
+		}
+	}
+	if config.Typosquats.Weight < 0 || config.Typosquats.Weight > maxSlatScore {
+		return nil, fmt.Errorf("typosquat weight must be between 0 and %d, is %d", maxSlatScore, config.Typosquats.Weight)
+	}
+
+	if config.Churn.Weight < 0 || config.Churn.Weight > maxSlatScore {
+		return nil, fmt.Errorf("churn weight must be between 0 and %d, is %d", maxSlatScore, config.Churn.Weight)
+	}
+
+	return &slatScorer{
+		rules:             rules,
+		external:          external,
+		analyzers:         analyzers,
+		profiles:          profiles,
+		patterns:          patterns,
+		keywords:          keywords,
+		dependencyWeights: dependencyWeights,
+		binaryWeights:     binaryWeights,
+		buildWeights:      buildWeights,
+		apiWeights:        apiWeights,
+		treeWeights:       treeWeights,
+		licenseWeights:    licenseWeights,
+		licenses:          config.Licenses,
+		typosquats:        config.Typosquats,
+		projectLicenses:   projectLicenses,
+		churn:             config.Churn,
+		contributors:      config.Contributors,
+		codeOwners:        config.CodeOwners,
+		signatures:        config.Signatures,
+		messages:          config.Messages,
+		tests:             config.Tests,
+		advisories:        config.Advisories,
+		vulnerabilities:   newVulnerabilityDB(config.Advisories.Dir),
+	}, nil
+}
+
+// mergeWeights overrides the default weights per kind with the configured ones.
+func mergeWeights(what string, defaults, configured map[string]int) (map[string]int, error) {
+	weights := make(map[string]int)
+	for kind, weight := range defaults {
+		weights[kind] = weight
+	}
+	for kind, weight := range configured {
+		if _, ok := defaults[kind]; !ok {
+			return nil, fmt.Errorf("unknown %s kind '%s'", what, kind)
+		}
+		if weight < 0 || weight > maxSlatScore {
+			return nil, fmt.Errorf("%s weight for '%s' must be between 0 and %d, is %d", what, kind, maxSlatScore, weight)
+		}
+		weights[kind] = weight
+	}
+	return weights, nil
+}
+
+func compileRule(conf ConfigRule) (*rule, error) {
+	if conf.Weight < 0 || conf.Weight > maxSlatScore {
+		return nil, fmt.Errorf("weight must be between 0 and %d, is %d", maxSlatScore, conf.Weight)
+	}
+	if len(conf.Paths) == 0 && len(conf.Messages) == 0 && len(conf.Authors) == 0 && conf.MinChurn == 0 {
+		return nil, fmt.Errorf("rule without any matcher")
+	}
+
+	r := &rule{name: conf.Name, weight: conf.Weight, minChurn: conf.MinChurn}
+	switch conf.Files {
+	case "", "all":
+	case FILE_HANDWRITTEN, FILE_GENERATED, FILE_VENDORED:
+		if len(conf.Paths) == 0 && conf.MinChurn == 0 {
+			return nil, fmt.Errorf("files without paths or min_churn")
+		}
+		r.files = conf.Files
+	default:
+		return nil, fmt.Errorf("illegal files '%s', must be all, %s, %s or %s", conf.Files, FILE_HANDWRITTEN, FILE_GENERATED, FILE_VENDORED)
+	}
+	for _, glob := range conf.Paths {
+		re, err := globToRegexp(glob)
+		if err != nil {
+			return nil, fmt.Errorf("illegal path glob '%s': %w", glob, err)
+		}
+		r.paths = append(r.paths, re)
+	}
+	for _, msg := range conf.Messages {
+		re, err := regexp.Compile(msg)
+		if err != nil {
+			return nil, fmt.Errorf("illegal message regex '%s': %w", msg, err)
+		}
+		r.messages = append(r.messages, re)
+	}
+	for _, author := range conf.Authors {
+		re, err := regexp.Compile(author)
+		if err != nil {
+			return nil, fmt.Errorf("illegal author regex '%s': %w", author, err)
+		}
+		r.authors = append(r.authors, re)
+	}
+	return r, nil
+}
+
+// globToRegexp converts a path glob to a regex. `*` and `?` do not match
+// a `/`, `**` matches across directories. A glob without a `/` is matched
+// against the file name only.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	prefix := "^"
+	if !strings.Contains(glob, "/") {
+		prefix = "^(.*/)?"
+	}
+	return regexp.Compile(prefix + globPattern(glob) + "$")
+}
+
+// globPattern converts the glob to an (unanchored) regex pattern.
+func globPattern(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// Reason explains why a rule contributed to the slat score of a commit.
+type Reason struct {
+	Rule         string
+	Match        string // the matched file, line or value
+	Contribution int
+}
+
+// Finding is something suspicious found in the added lines of a commit.
+type Finding struct {
+	File string
+	Line int
+	Kind string
+	Text string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d %s", f.File, f.Line, f.Text)
+}
+
 // slatScore calculates a slat (_s_hould-_l_ook-_a_t-i_t_) score between 0 and 100.0.
 // 100.0 you definitely need to look into it, 0.0 means there was nothing harmful detected in the
-// commit.
-func slatScore(diff *Diff) (int, error) {
+// commit. The returned reasons list every rule that contributed to the score.
+func (s *slatScorer) slatScore(history *projectHistory, commit *Commit, diff *Diff) (int, []Reason, error) {
+	reasons := make([]Reason, 0)
+	for _, r := range s.rules {
+		matched, details := r.matches(commit, diff)
+		if matched {
+			reasons = append(reasons, Reason{Rule: r.name, Match: strings.Join(details, ", "), Contribution: r.weight})
+		}
+	}
+
+	reasons = append(reasons, diff.External...)
+	reasons = append(reasons, analyzerReasons(s.analyzers, diff.Findings)...)
+	reasons = append(reasons, messageReasons(s.messages, s.keywords, commit)...)
+	reasons = append(reasons, s.dependencyReasons(diff.Dependencies)...)
+	reasons = append(reasons, vulnerabilityReasons(s.advisories, diff.Vulnerabilities)...)
+	if reason, ok := typosquatReason(s.typosquats, diff.Typosquats); ok {
+		reasons = append(reasons, reason)
+	}
+	reasons = append(reasons, binaryReasons(s.binaryWeights, diff.Binaries)...)
+	reasons = append(reasons, buildReasons(s.buildWeights, diff)...)
+	reasons = append(reasons, treeReasons(s.treeWeights, diff.TreeChanges)...)
+	reasons = append(reasons, licenseReasons(s.licenseWeights, diff.Licenses)...)
+	reasons = append(reasons, apiReasons(s.apiWeights, diff.APIChanges)...)
+
+	_, byTests := testReasons(s.tests, diff)
+	reasons = append(reasons, byTests...)
+
+	if reason, ok := churnReason(history.churn, diffChurn(diff), s.churn.Weight, s.churn.Threshold); ok {
+		reasons = append(reasons, reason)
+	}
 
+	_, byContributor := contributorReasons(history.contributors, s.contributors, commit)
+	reasons = append(reasons, byContributor...)
+
+	if reason, ok := signatureReason(s.signatures, history.signatures, commit); ok {
+		reasons = append(reasons, reason)
+	}
+
+	if reason, ok := codeOwnersReason(s.codeOwners, diff.Owners, commit); ok {
+		reasons = append(reasons, reason)
+	}
+
+	codeFindings := scanCode(s.patterns, diff.Patch)
+	for _, pattern := range s.patterns {
+		findings := codeFindings[pattern]
+		if len(findings) == 0 {
+			continue
+		}
+		match := findings[0].String()
+		if len(findings) > 1 {
+			match += fmt.Sprintf(" (+%d more)", len(findings)-1)
+		}
+		reasons = append(reasons, Reason{Rule: "code " + pattern.name, Match: match, Contribution: pattern.weight})
+	}
+
+	// a leaked secret always needs a look
+	for _, finding := range scanSecrets(diff.Patch) {
+		reasons = append(reasons, Reason{Rule: secretRulePrefix + finding.Kind, Match: finding.String(), Contribution: maxSlatScore})
+	}
+
+	profile := s.profile(commit.Project)
+	reasons = profile.applyWeights(reasons)
+	if len(history.factors) > 0 {
+		applyWeightFactors(history.factors, reasons)
+	}
+	return profile.aggregate(reasons), reasons, nil
+}
+
+func (s *slatScorer) dependencyReasons(deps []DependencyChange) []Reason {
+	reasons := make([]Reason, 0)
+	for _, dep := range deps {
+		if weight := s.dependencyWeights[dep.Kind]; weight > 0 {
+			reasons = append(reasons, Reason{Rule: "dependency " + dep.Kind, Match: dep.String(), Contribution: weight})
+		}
+		if dep.Kind == DEP_RETRACT || dep.Kind == DEP_REPLACE || dep.NewVersion == "" || !isPseudoVersion(dep.NewVersion) {
+			continue
+		}
+		if weight := s.dependencyWeights[DEP_PSEUDO_VERSION]; weight > 0 {
+			reasons = append(reasons, Reason{Rule: "dependency " + DEP_PSEUDO_VERSION, Match: dep.String(), Contribution: weight})
+		}
+	}
+	return reasons
+}
+
+// matches returns whether all matchers of the rule match and a description of
+// what each matcher matched on.
+func (r *rule) matches(commit *Commit, diff *Diff) (bool, []string) {
+	details := make([]string, 0)
+	if r.files != "" {
+		diff = selectFiles(diff, r.files)
+	}
+	if len(r.paths) > 0 {
+		file, ok := matchFile(r.paths, diff)
+		if !ok {
+			return false, nil
+		}
+		details = append(details, file)
+	}
+	if len(r.messages) > 0 {
+		line, ok := matchLine(r.messages, commit.Subject, commit.Message)
+		if !ok {
+			return false, nil
+		}
+		details = append(details, line)
+	}
+	if len(r.authors) > 0 {
+		name, ok := matchLine(r.authors, commit.AuthorName, commit.CommitterName)
+		if !ok {
+			return false, nil
+		}
+		details = append(details, name)
+	}
+	if r.minChurn > 0 {
+		c := churn(diff)
+		if c < r.minChurn {
+			return false, nil
+		}
+		details = append(details, fmt.Sprintf("%d lines changed", c))
+	}
+	return true, details
+}
+
+func matchFile(res []*regexp.Regexp, diff *Diff) (string, bool) {
 	for _, stat := range diff.Stats {
-		if touchesGoModules(stat.File) {
-			return 100.0, nil
+		file := path.Clean(newFileName(stat.File)) // renames are matched with the new name
+		for _, re := range res {
+			if re.MatchString(file) {
+				return file, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchLine returns the first line of the texts that matches any of the regexes.
+func matchLine(res []*regexp.Regexp, texts ...string) (string, bool) {
+	for _, re := range res {
+		for _, text := range texts {
+			for _, line := range strings.Split(text, "\n") {
+				if re.MatchString(line) {
+					return strings.TrimSpace(line), true
+				}
+			}
 		}
 	}
+	return "", false
+}
 
-	return 0.0, nil
+// selectFiles returns a diff with the numstat of the files of the class only.
+func selectFiles(diff *Diff, class string) *Diff {
+	stats := make([]NumStat, 0, len(diff.Stats))
+	for _, stat := range diff.Stats {
+		if diff.fileClass(stat.File) == class {
+			stats = append(stats, stat)
+		}
+	}
+	return &Diff{Stats: stats, Classes: diff.Classes}
 }
 
-func touchesGoModules(file string) bool {
-	return strings.Contains(file, "go.mod") || strings.Contains(file, "go.sum")
+func churn(diff *Diff) uint64 {
+	var sum uint64
+	for _, stat := range diff.Stats {
+		sum += stat.Added + stat.Deleted
+	}
+	return sum
 }
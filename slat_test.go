@@ -0,0 +1,148 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tc := []struct {
+		desc    string
+		glob    string
+		file    string
+		matches bool
+	}{
+		{desc: "plain file name in root", glob: "go.mod", file: "go.mod", matches: true},
+		{desc: "plain file name in sub folder", glob: "go.mod", file: "tools/go.mod", matches: true},
+		{desc: "plain file name prefix only", glob: "go.mod", file: "go.modx", matches: false},
+		{desc: "star in file name", glob: "*.sh", file: "scripts/install.sh", matches: true},
+		{desc: "star does not cross folders", glob: "src/*.rs", file: "src/a/b.rs", matches: false},
+		{desc: "double star crosses folders", glob: "src/**/*.rs", file: "src/a/b.rs", matches: true},
+		{desc: "double star matches zero folders", glob: "src/**/*.rs", file: "src/b.rs", matches: true},
+		{desc: "folder prefix", glob: ".github/**", file: ".github/workflows/ci.yml", matches: true},
+		{desc: "question mark", glob: "v?.txt", file: "v1.txt", matches: true},
+		{desc: "dot is literal", glob: "a.b", file: "axb", matches: false},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			re, err := globToRegexp(c.glob)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if re.MatchString(c.file) != c.matches {
+				t.Errorf("expected match(%s, %s) = %t", c.glob, c.file, c.matches)
+			}
+		})
+	}
+}
+
+func TestSlatScore(t *testing.T) {
+	rules := []ConfigRule{
+		{Name: "modules", Weight: 60, Paths: []string{"go.mod"}},
+		{Name: "security", Weight: 30, Messages: []string{"(?i)security"}},
+		{Name: "bot churn", Weight: 50, Authors: []string{"^dependabot"}, MinChurn: 100},
+	}
+	// the default keyword would overlap with the security rule
+	messages := ConfigMessages{Keywords: map[string]int{"security": 0}}
+	scorer, err := newSlatScorer(&Config{Rules: rules, Messages: messages})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	tc := []struct {
+		desc            string
+		commit          *Commit
+		diff            *Diff
+		expectedScore   int
+		expectedReasons []Reason
+	}{
+		{
+			desc:            "nothing matches",
+			commit:          &Commit{Subject: "fix typo", AuthorName: "alice"},
+			diff:            &Diff{Stats: []NumStat{{Added: 1, Deleted: 1, File: "README.md"}}},
+			expectedScore:   0,
+			expectedReasons: []Reason{},
+		},
+		{
+			desc:            "single rule",
+			commit:          &Commit{Subject: "bump deps", AuthorName: "alice"},
+			diff:            &Diff{Stats: []NumStat{{Added: 1, Deleted: 1, File: "go.mod"}}},
+			expectedScore:   60,
+			expectedReasons: []Reason{{Rule: "modules", Match: "go.mod", Contribution: 60}},
+		},
+		{
+			desc:            "renamed file matches with the new name",
+			commit:          &Commit{Subject: "move module", AuthorName: "alice"},
+			diff:            &Diff{Stats: []NumStat{{Added: 0, Deleted: 0, File: "sub/{old.mod => go.mod}"}, {Added: 0, Deleted: 0, File: "a.txt => b.txt"}}},
+			expectedScore:   60,
+			expectedReasons: []Reason{{Rule: "modules", Match: "sub/go.mod", Contribution: 60}},
+		},
+		{
+			desc:          "rules are summed up",
+			commit:        &Commit{Subject: "bump deps", Message: "Bumps x/net\nSecurity fix\n", AuthorName: "alice"},
+			diff:          &Diff{Stats: []NumStat{{Added: 1, Deleted: 1, File: "go.mod"}}},
+			expectedScore: 90,
+			expectedReasons: []Reason{
+				{Rule: "modules", Match: "go.mod", Contribution: 60},
+				{Rule: "security", Match: "Security fix", Contribution: 30},
+			},
+		},
+		{
+			desc:            "all matchers of a rule must match",
+			commit:          &Commit{Subject: "update", AuthorName: "dependabot[bot]"},
+			diff:            &Diff{Stats: []NumStat{{Added: 10, Deleted: 1, File: "main.go"}}},
+			expectedScore:   0,
+			expectedReasons: []Reason{},
+		},
+		{
+			desc:          "score is capped",
+			commit:        &Commit{Subject: "security update", CommitterName: "dependabot[bot]"},
+			diff:          &Diff{Stats: []NumStat{{Added: 100, Deleted: 1, File: "go.mod"}}},
+			expectedScore: 100,
+			expectedReasons: []Reason{
+				{Rule: "modules", Match: "go.mod", Contribution: 60},
+				{Rule: "security", Match: "security update", Contribution: 30},
+				{Rule: "bot churn", Match: "dependabot[bot], 101 lines changed", Contribution: 50},
+			},
+		},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			score, reasons, err := scorer.slatScore(&projectHistory{}, c.commit, c.diff)
+			if err != nil {
+				t.Errorf("unexpected error: %#v", err)
+			}
+			if score != c.expectedScore {
+				t.Errorf("expected score %d, got %d", c.expectedScore, score)
+			}
+			if diff := cmp.Diff(reasons, c.expectedReasons); diff != "" {
+				t.Errorf("unexpected reasons: %s", diff)
+			}
+		})
+	}
+}
+
+func TestNewSlatScorerErrors(t *testing.T) {
+	tc := []struct {
+		desc string
+		rule ConfigRule
+	}{
+		{desc: "no matcher", rule: ConfigRule{Name: "empty", Weight: 10}},
+		{desc: "weight too big", rule: ConfigRule{Name: "big", Weight: 101, Paths: []string{"*"}}},
+		{desc: "illegal regex", rule: ConfigRule{Name: "regex", Weight: 10, Messages: []string{"("}}},
+		{desc: "illegal files", rule: ConfigRule{Name: "files", Weight: 10, Paths: []string{"*"}, Files: "tests"}},
+		{desc: "files without file matcher", rule: ConfigRule{Name: "files", Weight: 10, Authors: []string{"bot"}, Files: FILE_VENDORED}},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			_, err := newSlatScorer(&Config{Rules: []ConfigRule{c.rule}})
+			if err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package deckard
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	TESTS_MISSING = "missing" // production code changed without any test change
+	TESTS_DELETED = "deleted" // test code removed
+)
+
+// test files per language, in addition to the testFolder ones
+var testFilePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`_test\.(go|dart|py|exs?)$`),
+	regexp.MustCompile(`(^|/)test_[^/]*\.py$`),
+	regexp.MustCompile(`(^|/)conftest\.py$`),
+	regexp.MustCompile(`[._](test|spec)\.[^/.]+$`),
+	regexp.MustCompile(`(Test|Tests|IT)\.(java|kt)$`),
+	regexp.MustCompile(`(^|/)src/test/`),
+	testFolder,
+}
+
+// languages whose files count as production code if they are no test files
+var sourceLanguages = map[string]bool{
+	"go":         true,
+	"rust":       true,
+	"python":     true,
+	"javascript": true,
+	"dart":       true,
+	"java":       true,
+}
+
+func isTestFile(file string) bool {
+	for _, re := range testFilePatterns {
+		if re.MatchString(file) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSourceFile(file string) bool {
+	return sourceLanguages[fileLanguage(file)] && !isTestFile(file)
+}
+
+// testChurn sums up the changed lines of handwritten production code and
+// tests. removed is the number of test lines a commit removed in files that
+// shrank.
+func testChurn(diff *Diff) (source, tests, removed uint64, shrunk []string) {
+	for _, stat := range diff.Stats {
+		file := newFileName(stat.File)
+		if stat.Binary || diff.fileClass(stat.File) != FILE_HANDWRITTEN {
+			continue
+		}
+		if isTestFile(file) {
+			tests += stat.Added + stat.Deleted
+			if stat.Deleted > stat.Added {
+				removed += stat.Deleted - stat.Added
+				shrunk = append(shrunk, file)
+			}
+		} else if isSourceFile(file) {
+			source += stat.Added + stat.Deleted
+		}
+	}
+	return source, tests, removed, shrunk
+}
+
+// testReasons classifies how the commit treats tests. The class is returned
+// even if its weight is 0, it is shown as a badge.
+func testReasons(conf ConfigTests, diff *Diff) (string, []Reason) {
+	reasons := make([]Reason, 0)
+	source, tests, removed, shrunk := testChurn(diff)
+	if conf.MinDeletedLines > 0 && removed >= uint64(conf.MinDeletedLines) {
+		if conf.DeletedWeight > 0 {
+			match := fmt.Sprintf("%d test lines removed in %s", removed, shrunk[0])
+			if len(shrunk) > 1 {
+				match += fmt.Sprintf(" (+%d more)", len(shrunk)-1)
+			}
+			reasons = append(reasons, Reason{Rule: "tests deleted", Match: match, Contribution: conf.DeletedWeight})
+		}
+		return TESTS_DELETED, reasons
+	}
+	if conf.MinSourceLines > 0 && source >= uint64(conf.MinSourceLines) && tests == 0 {
+		if conf.MissingWeight > 0 {
+			reasons = append(reasons, Reason{Rule: "untested change", Match: fmt.Sprintf("%d lines of code, no test changes", source), Contribution: conf.MissingWeight})
+		}
+		return TESTS_MISSING, reasons
+	}
+	return "", reasons
+}
+
+func testBadge(class string) string {
+	switch class {
+	case TESTS_MISSING:
+		return "🙈"
+	case TESTS_DELETED:
+		return "💀"
+	}
+	return ""
+}
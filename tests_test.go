@@ -0,0 +1,125 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIsTestFile(t *testing.T) {
+	tc := []struct {
+		file     string
+		expected bool
+	}{
+		{file: "repo.go", expected: false},
+		{file: "repo_test.go", expected: true},
+		{file: "pkg/testutil/util.go", expected: false},
+		{file: "tests/integration.rs", expected: true},
+		{file: "src/lib.rs", expected: false},
+		{file: "app/test_views.py", expected: true},
+		{file: "app/views_test.py", expected: true},
+		{file: "src/button.spec.tsx", expected: true},
+		{file: "src/__tests__/button.js", expected: true},
+		{file: "spec/models/user_spec.rb", expected: true},
+		{file: "src/test/java/FooTest.java", expected: true},
+		{file: "src/main/java/Foo.java", expected: false},
+		{file: "lib/widget_test.dart", expected: true},
+	}
+
+	for _, c := range tc {
+		t.Run(c.file, func(t *testing.T) {
+			if isTestFile(c.file) != c.expected {
+				t.Errorf("expected isTestFile(%s) = %t", c.file, c.expected)
+			}
+		})
+	}
+}
+
+func TestTestReasons(t *testing.T) {
+	conf := ConfigTests{MissingWeight: 30, MinSourceLines: 50, DeletedWeight: 40, MinDeletedLines: 20}
+
+	tc := []struct {
+		desc            string
+		conf            ConfigTests
+		diff            *Diff
+		expectedClass   string
+		expectedReasons []Reason
+	}{
+		{
+			desc:            "small change without tests",
+			conf:            conf,
+			diff:            &Diff{Stats: []NumStat{{File: "repo.go", Added: 10, Deleted: 5}}},
+			expectedClass:   "",
+			expectedReasons: []Reason{},
+		},
+		{
+			desc:            "change with tests",
+			conf:            conf,
+			diff:            &Diff{Stats: []NumStat{{File: "repo.go", Added: 100}, {File: "repo_test.go", Added: 1}}},
+			expectedClass:   "",
+			expectedReasons: []Reason{},
+		},
+		{
+			desc:            "docs and config do not count",
+			conf:            conf,
+			diff:            &Diff{Stats: []NumStat{{File: "README.md", Added: 100}, {File: "config.yaml", Added: 100}}},
+			expectedClass:   "",
+			expectedReasons: []Reason{},
+		},
+		{
+			desc:            "substantial change without tests",
+			conf:            conf,
+			diff:            &Diff{Stats: []NumStat{{File: "repo.go", Added: 40}, {File: "src/{a => b}/lib.rs", Added: 5, Deleted: 5}}},
+			expectedClass:   TESTS_MISSING,
+			expectedReasons: []Reason{{Rule: "untested change", Match: "50 lines of code, no test changes", Contribution: 30}},
+		},
+		{
+			desc: "generated code does not count",
+			conf: conf,
+			diff: &Diff{
+				Stats:   []NumStat{{File: "api/service.pb.go", Added: 500}, {File: "api/client.go", Added: 10}},
+				Classes: map[string]string{"api/service.pb.go": FILE_GENERATED},
+			},
+			expectedClass:   "",
+			expectedReasons: []Reason{},
+		},
+		{
+			desc: "tests deleted",
+			conf: conf,
+			diff: &Diff{Stats: []NumStat{
+				{File: "repo.go", Added: 5},
+				{File: "repo_test.go", Deleted: 15},
+				{File: "tests/fixtures.go", Added: 2, Deleted: 10},
+				{File: "slat_test.go", Added: 10, Deleted: 1},
+			}},
+			expectedClass:   TESTS_DELETED,
+			expectedReasons: []Reason{{Rule: "tests deleted", Match: "23 test lines removed in repo_test.go (+1 more)", Contribution: 40}},
+		},
+		{
+			desc:            "disabled weight keeps the class",
+			conf:            ConfigTests{MinSourceLines: 50, MinDeletedLines: 20},
+			diff:            &Diff{Stats: []NumStat{{File: "repo.go", Added: 100}}},
+			expectedClass:   TESTS_MISSING,
+			expectedReasons: []Reason{},
+		},
+		{
+			desc:            "disabled threshold",
+			conf:            ConfigTests{MissingWeight: 30, DeletedWeight: 40},
+			diff:            &Diff{Stats: []NumStat{{File: "repo.go", Added: 100}}},
+			expectedClass:   "",
+			expectedReasons: []Reason{},
+		},
+	}
+
+	for _, c := range tc {
+		t.Run(c.desc, func(t *testing.T) {
+			class, reasons := testReasons(c.conf, c.diff)
+			if class != c.expectedClass {
+				t.Errorf("expected class '%s', got '%s'", c.expectedClass, class)
+			}
+			if diff := cmp.Diff(reasons, c.expectedReasons); diff != "" {
+				t.Errorf("unexpected reasons: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package deckard
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	TREE_EXECUTABLE        = "executable"     // existing file became executable
+	TREE_NEW_EXECUTABLE    = "new_executable" // file added as executable
+	TREE_SYMLINK           = "symlink"        // symlink added or changed
+	TREE_SYMLINK_OUTSIDE   = "symlink_outside"
+	TREE_SUBMODULE_ADDED   = "submodule_added"
+	TREE_SUBMODULE_UPDATED = "submodule_updated" // gitlink points to another commit
+)
+
+// weights used if not overwritten in the config
+var defaultTreeWeights = map[string]int{
+	TREE_EXECUTABLE:        40,
+	TREE_NEW_EXECUTABLE:    10,
+	TREE_SYMLINK:           10,
+	TREE_SYMLINK_OUTSIDE:   80,
+	TREE_SUBMODULE_ADDED:   50,
+	TREE_SUBMODULE_UPDATED: 30,
+}
+
+var treeRuleNames = map[string]string{
+	TREE_EXECUTABLE:        "became executable",
+	TREE_NEW_EXECUTABLE:    "new executable",
+	TREE_SYMLINK:           "symlink",
+	TREE_SYMLINK_OUTSIDE:   "symlink outside repo",
+	TREE_SUBMODULE_ADDED:   "submodule added",
+	TREE_SUBMODULE_UPDATED: "submodule updated",
+}
+
+const (
+	modeExecutable = "100755"
+	modeSymlink    = "120000"
+	modeGitlink    = "160000"
+)
+
+// TreeChange is a change of a commit numstat does not show: file modes,
+// symlinks and submodules.
+type TreeChange struct {
+	File   string
+	Kind   string // one of the TREE_ constants
+	Detail string // symlink target or old and new submodule commit
+}
+
+// rawEntry is a file of `git diff --raw -z` output.
+type rawEntry struct {
+	oldMode string
+	newMode string
+	oldHash string
+	newHash string
+	status  byte // A, C, D, M, R, T or U
+	file    string
+}
+
+// parseRawDiff parses the output of `git diff --raw -z --no-abbrev`.
+func parseRawDiff(raw string) ([]rawEntry, error) {
+	entries := make([]rawEntry, 0)
+	fields := strings.Split(raw, "\x00")
+	for i := 0; i < len(fields); i++ {
+		if fields[i] == "" {
+			continue
+		}
+		header := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if !strings.HasPrefix(fields[i], ":") || len(header) != 5 || len(header[4]) == 0 {
+			return nil, fmt.Errorf("unexpected raw diff entry: %s", fields[i])
+		}
+		entry := rawEntry{oldMode: header[0], newMode: header[1], oldHash: header[2], newHash: header[3], status: header[4][0]}
+		paths := 1
+		if entry.status == 'R' || entry.status == 'C' {
+			paths = 2 // source and destination, the destination is the file after the commit
+		}
+		if i+paths >= len(fields) {
+			return nil, fmt.Errorf("missing path in raw diff entry: %s", fields[i])
+		}
+		i += paths
+		entry.file = fields[i]
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// treeChanges collects the mode, symlink and submodule changes of the commit.
+func treeChanges(targetFolder, hash string) ([]TreeChange, error) {
+	out, err := gitDiff(targetFolder, hash, "--raw", "-z", "--no-abbrev")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseRawDiff(string(out))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]TreeChange, 0)
+	for _, entry := range entries {
+		if entry.status == 'D' {
+			continue
+		}
+		switch entry.newMode {
+		case modeExecutable:
+			if entry.oldMode == modeExecutable {
+				continue
+			}
+			if entry.status == 'A' || entry.oldMode == "000000" {
+				changes = append(changes, TreeChange{File: entry.file, Kind: TREE_NEW_EXECUTABLE, Detail: entry.newMode})
+			} else {
+				changes = append(changes, TreeChange{File: entry.file, Kind: TREE_EXECUTABLE, Detail: entry.oldMode + " → " + entry.newMode})
+			}
+		case modeSymlink:
+			if entry.oldMode == modeSymlink && entry.oldHash == entry.newHash {
+				continue // renamed only
+			}
+			target, _, err := showFile(targetFolder, hash, entry.file)
+			if err != nil {
+				return nil, err
+			}
+			kind := TREE_SYMLINK
+			if symlinkOutside(entry.file, target) {
+				kind = TREE_SYMLINK_OUTSIDE
+			}
+			changes = append(changes, TreeChange{File: entry.file, Kind: kind, Detail: target})
+		case modeGitlink:
+			if entry.oldMode != modeGitlink {
+				changes = append(changes, TreeChange{File: entry.file, Kind: TREE_SUBMODULE_ADDED, Detail: shortHash(entry.newHash)})
+			} else if entry.oldHash != entry.newHash {
+				changes = append(changes, TreeChange{File: entry.file, Kind: TREE_SUBMODULE_UPDATED, Detail: shortHash(entry.oldHash) + " → " + shortHash(entry.newHash)})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// symlinkOutside is true if the target of the symlink file is outside of the repository.
+func symlinkOutside(file, target string) bool {
+	if path.IsAbs(target) || strings.HasPrefix(target, "~") {
+		return true
+	}
+	resolved := path.Join(path.Dir(file), target)
+	return resolved == ".." || strings.HasPrefix(resolved, "../")
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// treeReasons returns one reason per kind of tree change.
+func treeReasons(weights map[string]int, changes []TreeChange) []Reason {
+	byKind := make(map[string][]TreeChange)
+	for _, change := range changes {
+		byKind[change.Kind] = append(byKind[change.Kind], change)
+	}
+
+	reasons := make([]Reason, 0)
+	for _, kind := range []string{TREE_SYMLINK_OUTSIDE, TREE_EXECUTABLE, TREE_SUBMODULE_ADDED, TREE_SUBMODULE_UPDATED, TREE_SYMLINK, TREE_NEW_EXECUTABLE} {
+		kindChanges := byKind[kind]
+		if len(kindChanges) == 0 || weights[kind] == 0 {
+			continue
+		}
+		separator := " "
+		if kind == TREE_SYMLINK || kind == TREE_SYMLINK_OUTSIDE {
+			separator = " -> "
+		}
+		match := kindChanges[0].File + separator + kindChanges[0].Detail
+		if len(kindChanges) > 1 {
+			match += fmt.Sprintf(" (+%d more)", len(kindChanges)-1)
+		}
+		reasons = append(reasons, Reason{Rule: treeRuleNames[kind], Match: match, Contribution: weights[kind]})
+	}
+	return reasons
+}
@@ -0,0 +1,80 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRawDiff(t *testing.T) {
+	raw := ":100644 100755 1111111111111111111111111111111111111111 1111111111111111111111111111111111111111 M\x00a.sh\x00" +
+		":000000 120000 0000000000000000000000000000000000000000 2222222222222222222222222222222222222222 A\x00l nk\x00" +
+		":100644 100644 3333333333333333333333333333333333333333 4444444444444444444444444444444444444444 R087\x00old.go\x00new.go\x00" +
+		":160000 160000 5555555555555555555555555555555555555555 6666666666666666666666666666666666666666 M\x00sub\x00"
+
+	entries, err := parseRawDiff(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	expected := []rawEntry{
+		{oldMode: "100644", newMode: "100755", oldHash: "1111111111111111111111111111111111111111", newHash: "1111111111111111111111111111111111111111", status: 'M', file: "a.sh"},
+		{oldMode: "000000", newMode: "120000", oldHash: "0000000000000000000000000000000000000000", newHash: "2222222222222222222222222222222222222222", status: 'A', file: "l nk"},
+		{oldMode: "100644", newMode: "100644", oldHash: "3333333333333333333333333333333333333333", newHash: "4444444444444444444444444444444444444444", status: 'R', file: "new.go"},
+		{oldMode: "160000", newMode: "160000", oldHash: "5555555555555555555555555555555555555555", newHash: "6666666666666666666666666666666666666666", status: 'M', file: "sub"},
+	}
+	if diff := cmp.Diff(entries, expected, cmp.AllowUnexported(rawEntry{})); diff != "" {
+		t.Errorf("unexpected entries: %s", diff)
+	}
+
+	_, err = parseRawDiff("100644 100755 M\x00a.sh\x00")
+	if err == nil {
+		t.Errorf("expected error for a malformed entry")
+	}
+}
+
+func TestSymlinkOutside(t *testing.T) {
+	tc := []struct {
+		file     string
+		target   string
+		expected bool
+	}{
+		{file: "link", target: "README.md", expected: false},
+		{file: "docs/link", target: "../README.md", expected: false},
+		{file: "link", target: "../secrets", expected: true},
+		{file: "docs/link", target: "../../etc/passwd", expected: true},
+		{file: "link", target: "/etc/passwd", expected: true},
+		{file: "link", target: "~/.ssh/id_rsa", expected: true},
+		{file: "link", target: "..", expected: true},
+		{file: "link", target: "..foo", expected: false},
+	}
+
+	for _, c := range tc {
+		t.Run(c.file+" -> "+c.target, func(t *testing.T) {
+			if symlinkOutside(c.file, c.target) != c.expected {
+				t.Errorf("expected symlinkOutside(%s, %s) = %t", c.file, c.target, c.expected)
+			}
+		})
+	}
+}
+
+func TestTreeReasons(t *testing.T) {
+	changes := []TreeChange{
+		{File: "b.sh", Kind: TREE_NEW_EXECUTABLE, Detail: "100755"},
+		{File: "a.sh", Kind: TREE_EXECUTABLE, Detail: "100644 → 100755"},
+		{File: "c.sh", Kind: TREE_EXECUTABLE, Detail: "100644 → 100755"},
+		{File: "docs/link", Kind: TREE_SYMLINK_OUTSIDE, Detail: "../../etc/passwd"},
+		{File: "vendor/lib", Kind: TREE_SUBMODULE_UPDATED, Detail: "555555555555 → 666666666666"},
+	}
+	expected := []Reason{
+		{Rule: "symlink outside repo", Match: "docs/link -> ../../etc/passwd", Contribution: 80},
+		{Rule: "became executable", Match: "a.sh 100644 → 100755 (+1 more)", Contribution: 40},
+		{Rule: "submodule updated", Match: "vendor/lib 555555555555 → 666666666666", Contribution: 30},
+	}
+	weights, err := mergeWeights("tree", defaultTreeWeights, map[string]int{TREE_NEW_EXECUTABLE: 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if diff := cmp.Diff(treeReasons(weights, changes), expected); diff != "" {
+		t.Errorf("unexpected reasons: %s", diff)
+	}
+}
@@ -0,0 +1,254 @@
+package deckard
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// names with at most this many characters in the last path element only look
+// alike if their homoglyph skeletons match, too many legitimate short names
+// are an edit apart (vue, vuex)
+const shortPackageName = 5
+
+// popular packages of the ecosystems typosquats are checked for, extended by
+// the configured ones
+var defaultPopularPackages = map[string][]string{
+	"Go": {
+		"github.com/sirupsen/logrus", "github.com/stretchr/testify", "github.com/spf13/cobra", "github.com/spf13/viper",
+		"github.com/spf13/pflag", "github.com/gorilla/mux", "github.com/gorilla/websocket", "github.com/gin-gonic/gin",
+		"github.com/google/uuid", "github.com/google/go-cmp", "github.com/pkg/errors", "github.com/golang/protobuf",
+		"github.com/prometheus/client_golang", "github.com/go-sql-driver/mysql", "github.com/lib/pq",
+		"github.com/mattn/go-sqlite3", "github.com/aws/aws-sdk-go", "github.com/urfave/cli", "github.com/labstack/echo",
+		"github.com/go-redis/redis", "github.com/redis/go-redis", "github.com/jackc/pgx", "github.com/rs/zerolog",
+		"github.com/pelletier/go-toml", "github.com/BurntSushi/toml", "github.com/fsnotify/fsnotify",
+		"go.uber.org/zap", "google.golang.org/grpc", "google.golang.org/protobuf", "gopkg.in/yaml.v2", "gopkg.in/yaml.v3",
+		"golang.org/x/crypto", "golang.org/x/net", "golang.org/x/sys", "golang.org/x/text", "golang.org/x/term",
+		"golang.org/x/sync", "golang.org/x/tools", "golang.org/x/mod", "golang.org/x/oauth2", "golang.org/x/time",
+	},
+	"npm": {
+		"react", "react-dom", "preact", "vue", "angular", "lodash", "underscore", "express", "axios", "request", "chalk",
+		"commander", "debug", "moment", "dayjs", "webpack", "typescript", "jquery", "async", "uuid", "dotenv", "cross-env",
+		"eslint", "prettier", "electron", "colors", "event-stream", "babel-cli", "@babel/core", "jest", "mocha", "yargs",
+		"minimist", "glob", "rimraf", "mkdirp", "semver", "ws", "socket.io", "node-fetch", "bluebird", "coffee-script",
+	},
+	"crates.io": {
+		"serde", "serde_json", "serde_derive", "tokio", "rand", "clap", "regex", "log", "env_logger", "syn", "quote",
+		"proc-macro2", "libc", "reqwest", "hyper", "anyhow", "thiserror", "chrono", "lazy_static", "once_cell",
+		"futures", "bytes", "time", "itertools", "base64", "bitflags", "cfg-if", "tracing", "rustls", "openssl",
+	},
+}
+
+// Typosquat is an added dependency whose name looks like the one of an
+// existing or popular dependency.
+type Typosquat struct {
+	Manifest  string
+	Module    string // the added dependency
+	LooksLike string // the existing or popular dependency
+}
+
+func (t Typosquat) String() string {
+	return t.LooksLike + " vs " + t.Module
+}
+
+// homoglyphs are characters that are easily mistaken for another one.
+var homoglyphs = map[rune]rune{
+	'0': 'o', '1': 'l', 'I': 'l', '|': 'l', '5': 's', '_': '-',
+	// cyrillic and greek look-alikes of latin letters
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'х': 'x', 'у': 'y', 'і': 'i', 'ѕ': 's', 'ԁ': 'd', 'ӏ': 'l',
+	'α': 'a', 'ο': 'o', 'ν': 'v', 'ρ': 'p', 'τ': 't',
+}
+
+var homoglyphSequences = strings.NewReplacer("rn", "m", "vv", "w")
+
+// homoglyphSkeleton maps a name to a form in which look-alike names are equal.
+func homoglyphSkeleton(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if glyph, ok := homoglyphs[r]; ok {
+			r = glyph
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return homoglyphSequences.Replace(sb.String())
+}
+
+// editDistance is the Levenshtein distance of the names.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+var goMajorVersion = regexp.MustCompile(`(/v[0-9]+|\.v[0-9]+)$`)
+
+// packageBase strips the parts of a name that legitimately differ between
+// versions or spellings of the same package.
+func packageBase(ecosystem, name string) string {
+	switch ecosystem {
+	case "Go":
+		return goMajorVersion.ReplaceAllString(name, "")
+	case "crates.io":
+		return strings.ReplaceAll(name, "_", "-") // crates.io treats both the same
+	}
+	return name
+}
+
+// lookAlike returns the existing or popular package the name looks like, if
+// any. Candidates equal to the name (ignoring the major version) are not
+// look-alikes. Siblings of an existing dependency (e.g. another service
+// module of the same SDK) only look alike if their homoglyph skeletons match.
+func lookAlike(ecosystem, name string, existing, popular []string, maxDistance int) (string, bool) {
+	base := packageBase(ecosystem, name)
+	skeleton := homoglyphSkeleton(base)
+	siblings := make(map[string]bool)
+	if parent := parentPath(base); parent != "" {
+		for _, dep := range existing {
+			if parentPath(packageBase(ecosystem, dep)) == parent {
+				siblings[dep] = true
+			}
+		}
+	}
+
+	best, bestDistance := "", maxDistance+1
+	for _, candidate := range append(append([]string{}, existing...), popular...) {
+		candidateBase := packageBase(ecosystem, candidate)
+		if candidateBase == base {
+			return "", false // the same package in another version
+		}
+		if homoglyphSkeleton(candidateBase) == skeleton {
+			if bestDistance > 0 {
+				best, bestDistance = candidate, 0
+			}
+			continue
+		}
+		if siblings[candidate] || len(lastPathElement(base)) <= shortPackageName || len(lastPathElement(candidateBase)) <= shortPackageName {
+			continue
+		}
+		if distance := editDistance(base, candidateBase); distance <= maxDistance && distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best, best != ""
+}
+
+func lastPathElement(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// parentPath is the name without the last path element, empty if the name
+// has a single element.
+func parentPath(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// typosquats checks the dependencies added by the commit against the ones
+// the manifest had before and the popular packages of the ecosystem.
+func typosquats(conf ConfigTyposquats, targetFolder, hash string, deps []DependencyChange) ([]Typosquat, error) {
+	allowed := make(map[string]bool)
+	for _, name := range conf.Allowed {
+		allowed[name] = true
+	}
+
+	byManifest := make(map[string][]string)
+	manifests := make([]string, 0)
+	for _, dep := range deps {
+		if dep.Kind != DEP_ADDED || allowed[dep.Module] {
+			continue
+		}
+		if _, ok := byManifest[dep.Manifest]; !ok {
+			manifests = append(manifests, dep.Manifest)
+		}
+		byManifest[dep.Manifest] = append(byManifest[dep.Manifest], dep.Module)
+	}
+
+	squats := make([]Typosquat, 0)
+	reported := make(map[string]bool)
+	for _, manifest := range manifests {
+		analyzer := findManifestAnalyzer(manifest)
+		ecosystem := analyzer.ecosystem()
+		popular, ok := defaultPopularPackages[ecosystem]
+		if !ok {
+			continue
+		}
+
+		before, _, err := showFile(targetFolder, hash+"^", manifest)
+		if err != nil {
+			return nil, err
+		}
+		existing, err := manifestDependencies(analyzer, manifest, before)
+		if err != nil {
+			existing = nil // a manifest that does not parse has no known dependencies, the popular ones are still checked
+		}
+		sort.Strings(existing)
+		candidates := append(append([]string{}, popular...), conf.Popular[ecosystem]...)
+		sort.Strings(candidates)
+
+		for _, module := range byManifest[manifest] {
+			key := ecosystem + " " + module
+			if reported[key] {
+				continue // e.g. in package.json and package-lock.json
+			}
+			if known, ok := lookAlike(ecosystem, module, existing, candidates, conf.MaxDistance); ok {
+				reported[key] = true
+				squats = append(squats, Typosquat{Manifest: manifest, Module: module, LooksLike: known})
+			}
+		}
+	}
+	return squats, nil
+}
+
+// manifestDependencies returns the names of the dependencies of the
+// manifest, which are the ones added compared to an empty manifest.
+func manifestDependencies(analyzer manifestAnalyzer, manifest, content string) ([]string, error) {
+	changes, err := analyzer.diff(manifest, "", content)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(changes))
+	for _, change := range changes {
+		if change.Kind == DEP_ADDED {
+			names = append(names, change.Module)
+		}
+	}
+	return names, nil
+}
+
+// typosquatReason returns a reason if a look-alike dependency was added.
+func typosquatReason(conf ConfigTyposquats, squats []Typosquat) (Reason, bool) {
+	if len(squats) == 0 || conf.Weight == 0 {
+		return Reason{}, false
+	}
+	match := squats[0].String()
+	if len(squats) > 1 {
+		match += fmt.Sprintf(" (+%d more)", len(squats)-1)
+	}
+	return Reason{Rule: "typosquat", Match: match, Contribution: conf.Weight}, true
+}
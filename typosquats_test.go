@@ -0,0 +1,112 @@
+package deckard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEditDistance(t *testing.T) {
+	tc := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"logrus", "logrus", 0},
+		{"logrus", "1ogrus", 1},
+		{"crossenv", "cross-env", 1},
+		{"text", "term", 2},
+		{"", "abc", 3},
+		{"käse", "kase", 1},
+	}
+	for _, c := range tc {
+		if distance := editDistance(c.a, c.b); distance != c.expected {
+			t.Errorf("expected %d for %s/%s, got %d", c.expected, c.a, c.b, distance)
+		}
+	}
+}
+
+func TestLookAlike(t *testing.T) {
+	goCandidates := []string{"github.com/sirupsen/logrus", "golang.org/x/text", "github.com/spf13/cobra", "gopkg.in/yaml.v2"}
+	sdk := []string{"github.com/aws/aws-sdk-go-v2/service/sqs", "github.com/aws/aws-sdk-go-v2/service/dynamodb"}
+	tc := []struct {
+		ecosystem string
+		name      string
+		existing  []string
+		popular   []string
+		expected  string
+	}{
+		{"Go", "github.com/sirupsen/1ogrus", nil, goCandidates, "github.com/sirupsen/logrus"},
+		{"Go", "github.com/Sirupsen/logrus", nil, goCandidates, "github.com/sirupsen/logrus"},
+		{"Go", "github.com/sirupsen/logrus", nil, goCandidates, ""},
+		{"Go", "golang.org/x/term", nil, goCandidates, ""},
+		{"Go", "gopkg.in/yaml.v3", nil, goCandidates, ""},
+		{"Go", "github.com/spf13/cobra/v2", nil, goCandidates, ""},
+		{"Go", "github.com/spf13/c0bra", nil, goCandidates, "github.com/spf13/cobra"},
+		{"Go", "github.com/spf13/cobr4", nil, goCandidates, ""}, // short, not a homoglyph
+		{"Go", "github.com/spf13/cast", nil, goCandidates, ""},
+		{"Go", "github.com/aws/aws-sdk-go-v2/service/sns", sdk, goCandidates, ""},
+		{"Go", "github.com/aws/aws-sdk-go-v2/service/dynamodbx", sdk, goCandidates, ""},
+		{"Go", "github.com/aws/aws-sdk-go-v2/service/dynam0db", sdk, goCandidates, "github.com/aws/aws-sdk-go-v2/service/dynamodb"},
+		{"Go", "github.com/aws/aws-sdk-go-v2/servlce/dynamodb", sdk, goCandidates, "github.com/aws/aws-sdk-go-v2/service/dynamodb"},
+		{"npm", "crossenv", nil, []string{"cross-env", "express"}, "cross-env"},
+		{"npm", "expresss", nil, []string{"cross-env", "express"}, "express"},
+		{"npm", "reаct", nil, []string{"react"}, "react"}, // cyrillic a
+		{"npm", "rn-react", nil, []string{"react"}, ""},
+		{"npm", "preact", nil, []string{"react"}, ""},
+		{"npm", "preact", nil, []string{"react", "preact"}, ""},
+		{"npm", "vuex", nil, []string{"vue"}, ""},
+		{"npm", "color", []string{"colors"}, nil, ""},
+		{"crates.io", "slog", nil, []string{"log"}, ""},
+		{"crates.io", "serde-json", nil, []string{"serde_json"}, ""},
+		{"crates.io", "serde_jsom", nil, []string{"serde_json"}, "serde_json"},
+	}
+	for _, c := range tc {
+		known, ok := lookAlike(c.ecosystem, c.name, c.existing, c.popular, 2)
+		if known != c.expected || ok != (c.expected != "") {
+			t.Errorf("expected '%s' for %s, got '%s'", c.expected, c.name, known)
+		}
+	}
+}
+
+func TestManifestDependencies(t *testing.T) {
+	gomod := "module example.com/m\n\ngo 1.17\n\nrequire (\n\tgithub.com/sirupsen/logrus v1.8.1\n\tgolang.org/x/text v0.3.7\n)\n"
+	names, err := manifestDependencies(goModAnalyzer{}, "go.mod", gomod)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if diff := cmp.Diff(names, []string{"github.com/sirupsen/logrus", "golang.org/x/text"}); diff != "" {
+		t.Errorf("unexpected dependencies: %s", diff)
+	}
+}
+
+func TestTyposquatsMalformedParent(t *testing.T) {
+	folder := newTestRepo(t)
+	commitFiles(t, folder, map[string]string{"package.json": `{"dependencies": {"express": `})
+	commitFiles(t, folder, map[string]string{"package.json": `{"dependencies": {"expres": "^4.18.0"}}`})
+
+	deps := []DependencyChange{{Manifest: "package.json", Module: "expres", NewVersion: "^4.18.0", Kind: DEP_ADDED}}
+	squats, err := typosquats(ConfigTyposquats{MaxDistance: 2}, folder, "HEAD", deps)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	expected := []Typosquat{{Manifest: "package.json", Module: "expres", LooksLike: "express"}}
+	if diff := cmp.Diff(squats, expected); diff != "" {
+		t.Errorf("unexpected typosquats: %s", diff)
+	}
+}
+
+func TestTyposquatReason(t *testing.T) {
+	squats := []Typosquat{
+		{Manifest: "go.mod", Module: "github.com/sirupsen/1ogrus", LooksLike: "github.com/sirupsen/logrus"},
+		{Manifest: "go.mod", Module: "golang.org/x/crypt0", LooksLike: "golang.org/x/crypto"},
+	}
+	reason, ok := typosquatReason(ConfigTyposquats{Weight: 100}, squats)
+	expected := Reason{Rule: "typosquat", Match: "github.com/sirupsen/logrus vs github.com/sirupsen/1ogrus (+1 more)", Contribution: 100}
+	if !ok || reason != expected {
+		t.Errorf("unexpected reason: %#v", reason)
+	}
+	if _, ok := typosquatReason(ConfigTyposquats{Weight: 0}, squats); ok {
+		t.Errorf("expected no reason with weight 0")
+	}
+}
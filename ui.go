@@ -20,9 +20,11 @@ type DeckardUI struct {
 	projects *tview.TextView
 	status   *tview.TextView
 	commits  *tview.Table
+	details  *tview.TextView
 
 	config *Config
 	db     *sql.DB
+	scorer *slatScorer
 
 	state *uiState
 }
@@ -31,19 +33,41 @@ type uiState struct {
 	selectedProject int
 	status          string
 	commits         []*Commit
+	visibleCommits  []*Commit // commits currently shown in the table, in table order
+	fetching        bool      // the fetch started with the UI is still running
+	rescoring       bool
 }
 
 type Commit struct {
-	Project       string
-	Hash          string
-	Subject       string
-	Message       string
-	AuthorName    string
-	CommitterName string
-	CommitWhen    time.Time
-	State         string
-	Comment       *string
-	SlatScore     int // score between 0 and 100
+	Project         string
+	Hash            string
+	Subject         string
+	Message         string
+	AuthorName      string
+	AuthorEmail     string
+	CommitterName   string
+	CommitterEmail  string
+	CommitWhen      time.Time
+	State           string
+	Comment         *string
+	SlatScore       int // score between 0 and 100
+	Reasons         []Reason
+	Dependencies    []DependencyChange
+	LinesChanged    uint64
+	FilesChanged    uint64
+	Contributor     string   // one of the CONTRIBUTOR_ constants, empty for a regular contributor
+	Owners          []string // code owners of the changed files
+	Signature       string   // one of the SIGNATURE_ constants
+	SigningKey      string
+	References      []Reference // advisories mentioned in the message
+	APIChanges      []APIChange
+	Vulnerabilities []VulnerabilityMatch // known advisories of added or left dependency versions
+	Licenses        []LicenseChange
+	Findings        []AnalyzerFinding // new findings of the local analyzers
+	Tests           string            // one of the TESTS_ constants or empty
+	GeneratedLines  uint64            // part of LinesChanged in generated files
+	VendoredLines   uint64            // part of LinesChanged in vendored files
+	ScoringFailed   string            // errors of manifest parsing, the advisory dump, external scorers and analyzers, the score is incomplete if set
 }
 
 func newDeckardUi(app *tview.Application, state *uiState, config *Config, db *sql.DB) *DeckardUI {
@@ -72,6 +96,9 @@ func (ui *DeckardUI) ClearStatus() {
 }
 
 func (ui *DeckardUI) MarkAsReviewed(commit *Commit) {
+	if commit == nil {
+		return
+	}
 	err := UpdateCommitState(ui.db, commit.Project, commit.Hash, STATE_REVIEWED)
 	if err != nil {
 		fmt.Printf("ERR: %#v", err) //TODO proper error handling in UI
@@ -88,6 +115,18 @@ func (ui *DeckardUI) MarkAsReviewed(commit *Commit) {
 	updateCommitTable(ui)
 }
 
+// SetVerdict stores the verdict of the reviewer and removes the commit like MarkAsReviewed.
+func (ui *DeckardUI) SetVerdict(commit *Commit, verdict string) {
+	if commit == nil {
+		return
+	}
+	err := UpdateVerdict(ui.db, commit.Project, commit.Hash, verdict)
+	if err != nil {
+		fmt.Printf("ERR: %#v", err) //TODO proper error handling in UI
+	}
+	ui.MarkAsReviewed(commit)
+}
+
 func (ui *DeckardUI) AddCommits(commits []*Commit) {
 	//TODO make ui.state.commits a hashtable to prevent this O(n^2) check
 ADD_COMMIT:
@@ -113,6 +152,11 @@ func (ui *DeckardUI) Quit() {
 
 func BuildUI(config *Config, db *sql.DB) (*DeckardUI, error) {
 
+	scorer, err := newSlatScorer(config)
+	if err != nil {
+		return nil, err
+	}
+
 	initialState := &uiState{}
 
 	header := tview.NewFlex().SetDirection(tview.FlexColumn)
@@ -121,6 +165,7 @@ func BuildUI(config *Config, db *sql.DB) (*DeckardUI, error) {
 	projects := buildProjects(initialState, config)
 	status := buildStatus(initialState)
 	commits := buildCommits(initialState)
+	details := buildDetails()
 
 	flex := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(header.
@@ -128,11 +173,9 @@ func BuildUI(config *Config, db *sql.DB) (*DeckardUI, error) {
 			AddItem(status, 0, 50, false),
 			3, 100, false).
 		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
-			AddItem(commits, 0, 70, true),
-			//	AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
-			//		AddItem(tview.NewBox().SetBorder(true).SetTitle("Commit Details"), 0, 66, false).
-			//		AddItem(tview.NewBox().SetBorder(true).SetTitle("Project Metric"), 0, 34, false),
-			//		0, 30, false),
+			AddItem(commits, 0, 70, true).
+			AddItem(details, 0, 30, false),
+			//	AddItem(tview.NewBox().SetBorder(true).SetTitle("Project Metric"), 0, 34, false),
 			0, 100, false)
 
 	app := tview.NewApplication().SetRoot(flex, true).SetFocus(commits)
@@ -140,6 +183,12 @@ func BuildUI(config *Config, db *sql.DB) (*DeckardUI, error) {
 	ui.projects = projects
 	ui.status = status
 	ui.commits = commits
+	ui.details = details
+	ui.scorer = scorer
+
+	commits.SetSelectionChangedFunc(func(row, column int) {
+		updateDetails(ui)
+	})
 
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		return handleInput(ui, config, event)
@@ -164,11 +213,42 @@ func handleInput(ui *DeckardUI, config *Config, event *tcell.EventKey) *tcell.Ev
 		if event.Rune() == 'r' { // mark as reviewed
 			ui.MarkAsReviewed(selectedCommit(ui))
 		}
+		if event.Rune() == 'i' { // reviewed, was interesting
+			ui.SetVerdict(selectedCommit(ui), VERDICT_INTERESTING)
+		}
+		if event.Rune() == 'f' { // reviewed, false alarm
+			ui.SetVerdict(selectedCommit(ui), VERDICT_FALSE_ALARM)
+		}
 		if event.Rune() == 'q' { // mark as reviewed
 			ui.Quit()
 		}
+		if event.Rune() == 'R' { // rescore the stored commits of the selected project
+			if ui.state.rescoring {
+				return event
+			}
+			if ui.state.fetching { // both would write the same commits
+				ui.UpdateStatus("Rescoring is possible after the commit update has finished")
+				return event
+			}
+			ui.state.rescoring = true
+			go backgroundRescore(ui, selectedProjectName(ui.config, ui.state))
+		}
+		if event.Rune() == 'a' { // open the advisories mentioned in the commit message
+			commit := selectedCommit(ui)
+			if commit == nil {
+				return event
+			}
+			err := openReferences(commit)
+			if err != nil {
+				panic(err) //TODO proper ui dialog or status line
+			}
+		}
 		if event.Rune() == 'o' { // open commit in browser
-			err := openCommit(ui, selectedCommit(ui))
+			commit := selectedCommit(ui)
+			if commit == nil {
+				return event
+			}
+			err := openCommit(ui, commit)
 			if err != nil {
 				panic(err) //TODO proper ui dialog or status line
 			}
@@ -234,10 +314,30 @@ func openCommit(ui *DeckardUI, commit *Commit) error {
 	return browser.OpenURL(url)
 }
 
+func openReferences(commit *Commit) error {
+	browser.Stderr = nil
+	browser.Stdout = nil
+	for _, ref := range commit.References {
+		err := browser.OpenURL(ref.URL())
+		if err != nil {
+			return err
+		}
+	}
+	for _, vuln := range commit.Vulnerabilities {
+		err := browser.OpenURL(vuln.URL())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func selectedCommit(ui *DeckardUI) *Commit {
 	row, _ := ui.commits.GetSelection()
-	commit := ui.state.commits[row]
-	return commit
+	if row < 0 || row >= len(ui.state.visibleCommits) {
+		return nil
+	}
+	return ui.state.visibleCommits[row]
 }
 
 // ## project view
@@ -275,19 +375,27 @@ func updateProjectText(text *tview.TextView, state *uiState, config *Config) {
 	text.Highlight(SelectionMarker)
 }
 
+// getProjects returns the projects in selection order, "all" is always the
+// first one (selection 0) and the others are sorted by name.
 func getProjects(config *Config) []project {
 	projects := make([]project, 0)
-	projects = append(projects, project{
-		name: "all",
-		icon: "",
-	})
 	for id, data := range config.Projects {
 		projects = append(projects, project{name: id, icon: data.Icon})
 	}
 	sort.Slice(projects, func(i, j int) bool {
 		return projects[i].name < projects[j].name
 	})
-	return projects
+	return append([]project{{name: "all", icon: ""}}, projects...)
+}
+
+// selectedProjectName returns the name of the selected project, empty if all
+// projects are selected.
+func selectedProjectName(config *Config, state *uiState) string {
+	projects := getProjects(config)
+	if state.selectedProject <= 0 || state.selectedProject >= len(projects) {
+		return ""
+	}
+	return projects[state.selectedProject].name
 }
 
 // ## status view
@@ -316,26 +424,29 @@ func buildCommits(state *uiState) *tview.Table {
 func updateCommitTable(ui *DeckardUI) {
 	table := ui.commits
 
-	selectedPrjName := ""
-	if ui.state.selectedProject != 0 {
-		projects := getProjects(ui.config)
-		selectedPrjName = projects[ui.state.selectedProject].name
-	}
+	selectedPrjName := selectedProjectName(ui.config, ui.state)
 
 	table.Clear()
 	tablePos := 0
+	ui.state.visibleCommits = make([]*Commit, 0, len(ui.state.commits))
 	for _, commit := range ui.state.commits {
 		if selectedPrjName == "" || selectedPrjName == commit.Project {
+			ui.state.visibleCommits = append(ui.state.visibleCommits, commit)
 			colour := slatColour(commit.SlatScore)
 			setCell(table, tablePos, 0, lookupProjectIcon(ui, commit.Project), colour)
-			setCell(table, tablePos, 1, strconv.FormatInt(int64(commit.SlatScore), 10), colour)
+			score := strconv.FormatInt(int64(commit.SlatScore), 10)
+			if commit.ScoringFailed != "" {
+				score += "⚠"
+			}
+			setCell(table, tablePos, 1, score, colour)
 			setCell(table, tablePos, 2, commit.CommitWhen.Format("02.01 15:04"), colour)
 			setCell(table, tablePos, 3, commit.Hash[0:6], colour)
-			setCell(table, tablePos, 4, commit.AuthorName, colour)
-			setCell(table, tablePos, 5, commit.Subject, colour)
+			setCell(table, tablePos, 4, strings.TrimSpace(contributorBadge(commit.Contributor)+" "+commit.AuthorName), colour)
+			setCell(table, tablePos, 5, strings.TrimSpace(testBadge(commit.Tests)+" "+commit.Subject), colour)
 			tablePos++
 		}
 	}
+	updateDetails(ui)
 }
 
 func slatColour(score int) tcell.Color {
@@ -349,6 +460,93 @@ func setCell(table *tview.Table, row, column int, text string, colour tcell.Colo
 	table.SetCell(row, column, cell)
 }
 
+// ## commit details
+
+func buildDetails() *tview.TextView {
+	text := tview.NewTextView()
+	text.SetBorder(true).SetTitle("Commit Details")
+	text.SetDynamicColors(true)
+	text.SetWordWrap(true)
+	return text
+}
+
+func updateDetails(ui *DeckardUI) {
+	if ui.details == nil {
+		return
+	}
+	commit := selectedCommit(ui)
+	if commit == nil {
+		ui.details.SetText("")
+		return
+	}
+	ui.details.SetText(detailsText(commit))
+	ui.details.ScrollToBeginning()
+}
+
+func detailsText(commit *Commit) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[::b]%s[::-]\n", tview.Escape(commit.Subject))
+	fmt.Fprintf(&sb, "%s by %s\n", commit.Hash, tview.Escape(commit.AuthorName))
+	if commit.FilesChanged > 0 {
+		fmt.Fprintf(&sb, "Changed: %s\n", churnText(commit))
+	}
+	if len(commit.Owners) > 0 {
+		fmt.Fprintf(&sb, "Owners: %s\n", tview.Escape(strings.Join(commit.Owners, " ")))
+	}
+	if commit.Signature != "" {
+		fmt.Fprintf(&sb, "Signature: %s %s\n", signatureDescription(commit.Signature), tview.Escape(commit.SigningKey))
+	}
+	if len(commit.References) > 0 {
+		sb.WriteString("Advisories ('a' opens):\n")
+		for _, ref := range commit.References {
+			fmt.Fprintf(&sb, "  %s %s\n", ref.ID, ref.URL())
+		}
+	}
+	sb.WriteString("\n")
+	fmt.Fprintf(&sb, "[::b]Slat score %d[::-]\n", commit.SlatScore)
+	if commit.ScoringFailed != "" {
+		fmt.Fprintf(&sb, "[red]Scoring failed: %s[-]\n", tview.Escape(commit.ScoringFailed))
+	}
+	for _, reason := range commit.Reasons {
+		fmt.Fprintf(&sb, "+%d %s", reason.Contribution, tview.Escape(reason.Rule))
+		if reason.Match != "" {
+			fmt.Fprintf(&sb, ": %s", tview.Escape(reason.Match))
+		}
+		sb.WriteString("\n")
+	}
+	if len(commit.Dependencies) > 0 {
+		sb.WriteString("\n[::b]Dependencies[::-]\n")
+		for _, dep := range commit.Dependencies {
+			fmt.Fprintf(&sb, "%s: %s\n", dep.Kind, tview.Escape(dep.String()))
+		}
+	}
+	if len(commit.Vulnerabilities) > 0 {
+		sb.WriteString("\n[::b]Vulnerabilities ('a' opens)[::-]\n")
+		for _, vuln := range commit.Vulnerabilities {
+			fmt.Fprintf(&sb, "%s: %s\n", vuln.Kind, tview.Escape(vuln.String()))
+		}
+	}
+	if len(commit.Licenses) > 0 {
+		sb.WriteString("\n[::b]Licenses[::-]\n")
+		for _, change := range commit.Licenses {
+			fmt.Fprintf(&sb, "%s: %s\n", change.Kind, tview.Escape(change.String()))
+		}
+	}
+	if len(commit.Findings) > 0 {
+		sb.WriteString("\n[::b]Analyzer findings[::-]\n")
+		for _, finding := range commit.Findings {
+			fmt.Fprintf(&sb, "%s: %s\n", finding.Analyzer, tview.Escape(finding.Text))
+		}
+	}
+	if len(commit.APIChanges) > 0 {
+		sb.WriteString("\n[::b]API changes[::-]\n")
+		for _, change := range commit.APIChanges {
+			fmt.Fprintf(&sb, "%s: %s\n", change.Kind, tview.Escape(change.String()))
+		}
+	}
+	return sb.String()
+}
+
 func lookupProjectIcon(ui *DeckardUI, project string) string {
 	for prj, conf := range ui.config.Projects {
 		if prj == project {
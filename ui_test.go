@@ -4,6 +4,25 @@ import (
 	"testing"
 )
 
+func TestSelectedProjectName(t *testing.T) {
+	config := &Config{Projects: map[string]ConfigProject{"zeta": {}, "alpha": {}, "beta": {}}}
+	tc := []struct {
+		selected int
+		expected string
+	}{
+		{0, ""},
+		{1, "alpha"},
+		{2, "beta"},
+		{3, "zeta"},
+		{4, ""},
+	}
+	for _, c := range tc {
+		if name := selectedProjectName(config, &uiState{selectedProject: c.selected}); name != c.expected {
+			t.Errorf("expected '%s' for %d, got '%s'", c.expected, c.selected, name)
+		}
+	}
+}
+
 func TestRepoURL(t *testing.T) {
 	tc := []struct {
 		desc        string
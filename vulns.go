@@ -0,0 +1,319 @@
+package deckard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	VULN_AFFECTED = "vulnerable" // the commit adds or moves to an affected version
+	VULN_FIXED    = "fixed"      // the commit moves away from an affected version
+)
+
+// VulnerabilityMatch is a known advisory for a dependency version a commit
+// adds or leaves.
+type VulnerabilityMatch struct {
+	Manifest string
+	Module   string
+	Version  string
+	ID       string // OSV, GHSA, ... identifier of the advisory
+	Kind     string // one of the VULN_ constants
+}
+
+func (m VulnerabilityMatch) String() string {
+	return fmt.Sprintf("%s %s %s", m.Module, m.Version, m.ID)
+}
+
+func (m VulnerabilityMatch) URL() string {
+	return "https://osv.dev/vulnerability/" + m.ID
+}
+
+// osvRecord is the part of an advisory in the OSV format that is needed for
+// matching. GHSA dumps (github/advisory-database) use the same format.
+type osvRecord struct {
+	ID        string        `json:"id"`
+	Aliases   []string      `json:"aliases"`
+	Withdrawn string        `json:"withdrawn"`
+	Affected  []osvAffected `json:"affected"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []osvRange `json:"ranges"`
+	Versions []string   `json:"versions"`
+}
+
+type osvRange struct {
+	Type   string     `json:"type"`
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+	Limit        string `json:"limit"`
+}
+
+type osvPackage struct {
+	ecosystem string
+	name      string
+}
+
+// osvEntry is an affected package of an advisory.
+type osvEntry struct {
+	ids      []string // ID and aliases
+	affected osvAffected
+}
+
+// vulnerabilityDB matches dependency versions against a local dump of OSV
+// advisories. The dump is read on first use.
+type vulnerabilityDB struct {
+	dir       string
+	once      sync.Once
+	err       error
+	byPackage map[osvPackage][]*osvEntry
+	skipped   []string // advisories that did not parse, e.g. while the dump is synced
+}
+
+func newVulnerabilityDB(dir string) *vulnerabilityDB {
+	if dir == "" {
+		return nil
+	}
+	return &vulnerabilityDB{dir: dir}
+}
+
+// load reads all .json files below the dump folder. Files that do not parse
+// are skipped.
+func (v *vulnerabilityDB) load() error {
+	v.byPackage = make(map[osvPackage][]*osvEntry)
+	return filepath.WalkDir(v.dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(file, ".json") {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var record osvRecord
+		err = json.Unmarshal(content, &record)
+		if err != nil {
+			v.skipped = append(v.skipped, fmt.Sprintf("%s: %s", file, err))
+			return nil
+		}
+		v.add(&record)
+		return nil
+	})
+}
+
+func (v *vulnerabilityDB) add(record *osvRecord) {
+	if record.ID == "" || record.Withdrawn != "" {
+		return
+	}
+	ids := append([]string{record.ID}, record.Aliases...)
+	for _, affected := range record.Affected {
+		ecosystem := affected.Package.Ecosystem
+		if i := strings.Index(ecosystem, ":"); i >= 0 {
+			ecosystem = ecosystem[:i] // e.g. "Debian:11"
+		}
+		pkg := osvPackage{ecosystem: ecosystem, name: osvPackageName(ecosystem, affected.Package.Name)}
+		v.byPackage[pkg] = append(v.byPackage[pkg], &osvEntry{ids: ids, affected: affected})
+	}
+}
+
+func osvPackageName(ecosystem, name string) string {
+	if ecosystem == "PyPI" {
+		return normalizePythonName(name)
+	}
+	return name
+}
+
+// match finds the advisories of the versions the dependency changes add and
+// of the ones they leave. A nil database matches nothing. Skipped advisories
+// are returned as text for the commit, the matches may be incomplete.
+func (v *vulnerabilityDB) match(deps []DependencyChange) ([]VulnerabilityMatch, string, error) {
+	if v == nil {
+		return nil, "", nil
+	}
+	v.once.Do(func() {
+		v.err = v.load()
+	})
+	if v.err != nil {
+		return nil, "", v.err
+	}
+	failures := ""
+	if len(v.skipped) > 0 && len(deps) > 0 {
+		failures = fmt.Sprintf("advisories skipped (%d), e.g. %s", len(v.skipped), v.skipped[0])
+	}
+
+	matches := make([]VulnerabilityMatch, 0)
+	for _, dep := range deps {
+		if dep.Kind == DEP_REPLACE || dep.Kind == DEP_RETRACT {
+			continue
+		}
+		analyzer := findManifestAnalyzer(dep.Manifest)
+		if analyzer == nil {
+			continue
+		}
+		pkg := osvPackage{ecosystem: analyzer.ecosystem(), name: osvPackageName(analyzer.ecosystem(), dep.Module)}
+		if dep.Kind == DEP_TOOLCHAIN {
+			pkg.name = "stdlib" // go toolchain versions, e.g. go1.21.3
+		}
+		entries := v.byPackage[pkg]
+		if len(entries) == 0 {
+			continue
+		}
+
+		newIDs := make(map[string]bool)
+		for _, version := range splitVersions(dep.NewVersion, dep.Kind) {
+			for _, id := range affectingIDs(entries, version) {
+				newIDs[id] = true
+				matches = append(matches, VulnerabilityMatch{Manifest: dep.Manifest, Module: dep.Module, Version: version, ID: id, Kind: VULN_AFFECTED})
+			}
+		}
+		for _, version := range splitVersions(dep.OldVersion, dep.Kind) {
+			for _, id := range affectingIDs(entries, version) {
+				if !newIDs[id] {
+					matches = append(matches, VulnerabilityMatch{Manifest: dep.Manifest, Module: dep.Module, Version: version, ID: id, Kind: VULN_FIXED})
+				}
+			}
+		}
+	}
+	return matches, failures, nil
+}
+
+// splitVersions returns the versions of a (lock file) version list, with
+// range operators stripped.
+func splitVersions(versions, kind string) []string {
+	result := make([]string, 0)
+	for _, version := range strings.Split(versions, ", ") {
+		version = normalizeVersion(version)
+		if kind == DEP_TOOLCHAIN {
+			version = strings.TrimPrefix(version, "go")
+		}
+		if version != "" {
+			result = append(result, version)
+		}
+	}
+	return result
+}
+
+// affectingIDs returns the IDs of the advisories affecting the version. An
+// advisory that is in the dump multiple times (e.g. as GO- and as GHSA-
+// advisory) is only returned once.
+func affectingIDs(entries []*osvEntry, version string) []string {
+	seen := make(map[string]bool)
+	ids := make([]string, 0)
+	for _, entry := range entries {
+		if seen[entry.ids[0]] || !entry.affected.affects(version) {
+			continue
+		}
+		for _, id := range entry.ids {
+			seen[id] = true
+		}
+		ids = append(ids, entry.ids[0])
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// affects reports whether the version is listed or within one of the
+// SEMVER or ECOSYSTEM ranges. Ecosystem versions are compared as semver,
+// which is close enough for most of them.
+func (a *osvAffected) affects(version string) bool {
+	for _, listed := range a.Versions {
+		if strings.TrimPrefix(listed, "v") == strings.TrimPrefix(version, "v") {
+			return true
+		}
+	}
+	if len(parseSemver(version).parts) == 0 {
+		return false
+	}
+	for _, r := range a.Ranges {
+		if (r.Type == "SEMVER" || r.Type == "ECOSYSTEM") && r.affects(version) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *osvRange) affects(version string) bool {
+	events := make([]osvEvent, len(r.Events))
+	copy(events, r.Events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return compareSemver(events[i].version(), events[j].version()) < 0
+	})
+
+	affected := false
+	for _, event := range events {
+		switch {
+		case event.Introduced != "":
+			if event.Introduced == "0" || compareSemver(version, event.Introduced) >= 0 {
+				affected = true
+			}
+		case event.Fixed != "":
+			if compareSemver(version, event.Fixed) >= 0 {
+				affected = false
+			}
+		case event.LastAffected != "":
+			if compareSemver(version, event.LastAffected) > 0 {
+				affected = false
+			}
+		case event.Limit != "":
+			if compareSemver(version, event.Limit) >= 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func (e osvEvent) version() string {
+	for _, version := range []string{e.Introduced, e.Fixed, e.LastAffected, e.Limit} {
+		if version != "" {
+			return version
+		}
+	}
+	return ""
+}
+
+// vulnerabilityReasons returns one reason per kind of match.
+func vulnerabilityReasons(conf ConfigAdvisories, matches []VulnerabilityMatch) []Reason {
+	byKind := make(map[string][]VulnerabilityMatch)
+	for _, match := range matches {
+		byKind[match.Kind] = append(byKind[match.Kind], match)
+	}
+
+	reasons := make([]Reason, 0)
+	for _, kind := range []struct {
+		kind   string
+		rule   string
+		weight int
+	}{
+		{VULN_AFFECTED, "vulnerable dependency", conf.VulnerableWeight},
+		{VULN_FIXED, "fixed vulnerability", conf.FixedWeight},
+	} {
+		kindMatches := byKind[kind.kind]
+		if len(kindMatches) == 0 || kind.weight == 0 {
+			continue
+		}
+		match := kindMatches[0].String()
+		if len(kindMatches) > 1 {
+			match += fmt.Sprintf(" (+%d more)", len(kindMatches)-1)
+		}
+		reasons = append(reasons, Reason{Rule: kind.rule, Match: match, Contribution: kind.weight})
+	}
+	return reasons
+}
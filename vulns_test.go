@@ -0,0 +1,114 @@
+package deckard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOSVRangeAffects(t *testing.T) {
+	r := osvRange{Type: "SEMVER", Events: []osvEvent{
+		{Introduced: "2.0.0"}, {Fixed: "2.3.1"}, {Introduced: "0"}, {Fixed: "1.5.0"}, {Introduced: "3.0.0"}, {LastAffected: "3.1.0"},
+	}}
+	tc := []struct {
+		version  string
+		expected bool
+	}{
+		{"0.1.0", true},
+		{"v1.4.9", true},
+		{"1.5.0", false},
+		{"1.9.0", false},
+		{"2.0.0", true},
+		{"2.3.0", true},
+		{"2.3.1", false},
+		{"3.1.0", true},
+		{"3.1.1", false},
+	}
+	for _, c := range tc {
+		if affected := r.affects(c.version); affected != c.expected {
+			t.Errorf("expected %v for %s, got %v", c.expected, c.version, affected)
+		}
+	}
+}
+
+func writeAdvisory(t *testing.T, dir, name, content string) {
+	err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVulnerabilityMatch(t *testing.T) {
+	dir := t.TempDir()
+	writeAdvisory(t, dir, "go/GO-2021-0113.json", `{"id": "GO-2021-0113", "aliases": ["GHSA-ppp9-7jff-5vj2"], "affected": [
+		{"package": {"ecosystem": "Go", "name": "golang.org/x/text"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.3.7"}]}]}]}`)
+	writeAdvisory(t, dir, "ghsa/GHSA-ppp9-7jff-5vj2.json", `{"id": "GHSA-ppp9-7jff-5vj2", "aliases": ["GO-2021-0113"], "affected": [
+		{"package": {"ecosystem": "Go", "name": "golang.org/x/text"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.3.7"}]}]}]}`)
+	writeAdvisory(t, dir, "pypi/PYSEC-1.json", `{"id": "PYSEC-1", "affected": [
+		{"package": {"ecosystem": "PyPI", "name": "Py_YAML"}, "versions": ["5.3"]}]}`)
+	writeAdvisory(t, dir, "go/GO-2023-0001.json", `{"id": "GO-2023-0001", "affected": [
+		{"package": {"ecosystem": "Go", "name": "stdlib"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "1.21.0"}, {"fixed": "1.21.4"}]}]}]}`)
+	writeAdvisory(t, dir, "go/GO-2020-0001.json", `{"id": "GO-2020-0001", "withdrawn": "2021-01-01T00:00:00Z", "affected": [
+		{"package": {"ecosystem": "Go", "name": "github.com/a/b"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]}]}`)
+	writeAdvisory(t, dir, "README.md", "not an advisory")
+
+	deps := []DependencyChange{
+		{Manifest: "go.mod", Module: "golang.org/x/text", NewVersion: "v0.3.5", Kind: DEP_ADDED},
+		{Manifest: "sub/go.mod", Module: "golang.org/x/text", OldVersion: "v0.3.6", NewVersion: "v0.3.8", Kind: DEP_UPGRADED},
+		{Manifest: "go.mod", Module: "toolchain", OldVersion: "go1.20.1", NewVersion: "go1.21.3", Kind: DEP_TOOLCHAIN},
+		{Manifest: "go.mod", Module: "github.com/a/b", NewVersion: "v1.0.0", Kind: DEP_ADDED},
+		{Manifest: "requirements.txt", Module: "py-yaml", OldVersion: "==5.3", Kind: DEP_REMOVED},
+		{Manifest: "Cargo.toml", Module: "serde", NewVersion: "1.0.0", Kind: DEP_ADDED},
+	}
+	matches, failures, err := newVulnerabilityDB(dir).match(deps)
+	if err != nil || failures != "" {
+		t.Fatalf("unexpected error: %#v, %s", err, failures)
+	}
+	expected := []VulnerabilityMatch{
+		{Manifest: "go.mod", Module: "golang.org/x/text", Version: "v0.3.5", ID: "GHSA-ppp9-7jff-5vj2", Kind: VULN_AFFECTED},
+		{Manifest: "sub/go.mod", Module: "golang.org/x/text", Version: "v0.3.6", ID: "GHSA-ppp9-7jff-5vj2", Kind: VULN_FIXED},
+		{Manifest: "go.mod", Module: "toolchain", Version: "1.21.3", ID: "GO-2023-0001", Kind: VULN_AFFECTED},
+		{Manifest: "requirements.txt", Module: "py-yaml", Version: "5.3", ID: "PYSEC-1", Kind: VULN_FIXED},
+	}
+	if diff := cmp.Diff(matches, expected); diff != "" {
+		t.Errorf("unexpected matches: %s", diff)
+	}
+
+	var noDB *vulnerabilityDB
+	matches, _, err = noDB.match(deps)
+	if err != nil || len(matches) != 0 {
+		t.Errorf("expected no matches without a dump, got %v, %v", matches, err)
+	}
+
+	writeAdvisory(t, dir, "go/GO-2024-0001.json", `{"id": "GO-2024-0001", "affe`) // still syncing
+	brokenMatches, failures, err := newVulnerabilityDB(dir).match(deps)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if diff := cmp.Diff(brokenMatches, expected); diff != "" {
+		t.Errorf("unexpected matches with a broken advisory: %s", diff)
+	}
+	if !strings.HasPrefix(failures, "advisories skipped (1), e.g. "+filepath.Join(dir, "go/GO-2024-0001.json")+": ") {
+		t.Errorf("unexpected failures: %s", failures)
+	}
+}
+
+func TestVulnerabilityReasons(t *testing.T) {
+	conf := ConfigAdvisories{VulnerableWeight: 80, FixedWeight: 0}
+	matches := []VulnerabilityMatch{
+		{Module: "a", Version: "1.0", ID: "GHSA-1", Kind: VULN_AFFECTED},
+		{Module: "b", Version: "2.0", ID: "GHSA-2", Kind: VULN_AFFECTED},
+		{Module: "c", Version: "3.0", ID: "GHSA-3", Kind: VULN_FIXED},
+	}
+	expected := []Reason{{Rule: "vulnerable dependency", Match: "a 1.0 GHSA-1 (+1 more)", Contribution: 80}}
+	if diff := cmp.Diff(vulnerabilityReasons(conf, matches), expected); diff != "" {
+		t.Errorf("unexpected reasons: %s", diff)
+	}
+}